@@ -0,0 +1,96 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// tenseStats requires to display the results by tense instead of drilling
+var tenseStats bool
+
+// conjugateCmd represents the conjugate command
+var conjugateCmd = &cobra.Command{
+	Use:   "conjugate [numbers]",
+	Short: "Drills the conjugation tables of the lessons",
+	Long: `This command asks random cells of the conjugation tables of the lessons
+selected as for the lessons command, such as "aller, futur, nous?". The tables
+are written in the lessons file after a "### Conjugation Lesson" line, one row
+per verb and tense, after the persons of the tables:
+  ### Conjugation Lesson 01
+  persons;je;tu;il;nous;vous;ils
+  aller;présent;vais;vas;va;allons;allez;vont
+  aller;futur;irai;iras;ira;irons;irez;iront
+A form written - is a person the verb has no form for. Without a persons line,
+the persons are 1sg, 2sg, 3sg, 1pl, 2pl and 3pl.
+The answers typed in interactive mode are recorded: use --stats to see the
+tenses you miss the most, and --tags with the name of a tense (futur,
+passé-composé...) to drill only this tense. --weighted asks more often the
+cells you missed.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			tools.NegativeStatus("Please supply lessons number. Check the syntax of the command if you don't know how to set lessons number.")
+			os.Exit(1)
+		}
+		lessonNumbers := toLessonNumbers(args[0])
+		topic := loadTopic()
+		if tenseStats {
+			history, err := datamodel.LoadHistory(params.GetHistoryFile())
+			if err != nil {
+				tools.Error(err, "failed to read the results")
+				os.Exit(1)
+			}
+			if !engine.WriteTenseStats(os.Stdout, topic, history, lessonNumbers) {
+				tools.Warning(fmt.Sprintf("There is no conjugation table in the lessons %s.", args[0]))
+			}
+			return
+		}
+		qa := buildQuestionsSet(topic, datamodel.ExerciseConjugation, lessonNumbers)
+		if qa.GetCount() == 0 {
+			tools.NegativeStatus(fmt.Sprintf("There is no conjugation table in the lessons %s.", args[0]))
+			os.Exit(1)
+		}
+		params.SetExercise(datamodel.ExerciseConjugation)
+		params.SetListOfSubsections(lessonNumbers...)
+		params.SetLanguages(topic.NativeLanguage, topic.LearnedLanguage)
+		if err := engine.AskQuestions(qa, params); err != nil {
+			tools.Error(err, "failed to drill the conjugations")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(conjugateCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// conjugateCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// conjugateCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	conjugateCmd.Flags().BoolVarP(&tenseStats, "stats", "", false, "Displays your results tense by tense, the weakest tenses first, instead of drilling.")
+}
@@ -0,0 +1,97 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/quiz"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// quizAddr is the address the players of the quiz join
+var quizAddr string
+
+// answerTime is the time given to the players to answer a question
+var answerTime time.Duration
+
+// quizExercise is the kind of questions of the quiz
+var quizExercise string
+
+// hostCmd represents the host command
+var hostCmd = &cobra.Command{
+	Use:   "host [numbers]",
+	Short: "Hosts a quiz on the lessons for the players of the local network",
+	Long: `This command hosts a quiz on the lessons selected as for the lessons command.
+The players join it from their own machine with:
+  repeatit join <address of the host>:<port>
+Press Return once everybody has joined to start the quiz. Everyone gets the
+same question at the same time. A correct answer wins 100 points, plus a bonus
+of up to 100 points for answering quickly. The scoreboard is displayed after
+each question.
+The questions are asked once, in a random order. --max-questions limits their
+number and --mixed mixes their directions.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			tools.NegativeStatus("Please supply lessons number. Check the syntax of the command if you don't know how to set lessons number.")
+			os.Exit(1)
+		}
+		checkExercise(quizExercise)
+		topic := loadTopic()
+		params.SetExercise(quizExercise)
+		qa := buildQuestionsSet(topic, quizExercise, toLessonNumbers(args[0]))
+		if qa.GetCount() == 0 {
+			tools.NegativeStatus("Number of questions is zero. Please check your lessons selection.")
+			os.Exit(1)
+		}
+		host := quiz.NewHost(qa, params, os.Stdout)
+		host.SetAnswerTime(answerTime)
+		if err := host.Listen(quizAddr); err != nil {
+			tools.Error(err, "failed to host the quiz")
+			os.Exit(1)
+		}
+		defer host.Close()
+		tools.PositiveStatus(fmt.Sprintf("The players can join with: repeatit join %s", host.GetAddr()))
+		fmt.Println("Press Return to start the quiz once everybody has joined.")
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		if err := host.Play(); err != nil {
+			tools.Error(err, "the quiz has failed")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hostCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// hostCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// hostCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	hostCmd.Flags().StringVarP(&quizAddr, "addr", "", ":4242", "Address the players join. By default, any interface of the machine on port 4242.")
+	hostCmd.Flags().DurationVarP(&answerTime, "answer-time", "", quiz.DefaultAnswerTime, "Time given to the players to answer a question.")
+	hostCmd.Flags().StringVarP(&quizExercise, "exercise", "", datamodel.ExerciseVocabulary, `Kind of questions: vocabulary, sentences, lessons (the sentences of each
+lesson after its vocabulary), cloze, scramble, forms, grammar or conjugation.`)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cmd
+
+import (
+	"os"
+	"os/user"
+
+	"github.com/boris-lenzinger/repeatit/quiz"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// playerName is the name displayed to the other players of the quiz
+var playerName string
+
+// joinCmd represents the join command
+var joinCmd = &cobra.Command{
+	Use:   "join <host:port>",
+	Short: "Joins a quiz hosted on the local network",
+	Long: `This command joins the quiz started by the host command on another machine.
+Type your answer when a question is displayed, then Return: only your first
+answer counts. A correct answer wins 100 points, plus a bonus of up to 100
+points for answering quickly.
+`,
+	// the questions come from the host: no lessons file is needed
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			tools.NegativeStatus("Please supply the address of the host, for instance 192.168.1.12:4242.")
+			os.Exit(1)
+		}
+		name := playerName
+		if name == "" {
+			name = defaultPlayerName()
+		}
+		if err := quiz.Join(args[0], name, os.Stdin, os.Stdout); err != nil {
+			tools.Error(err, "the quiz has failed")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(joinCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// joinCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// joinCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	joinCmd.Flags().StringVarP(&playerName, "name", "", "", "Name displayed to the other players. Defaults to your user name.")
+}
+
+// defaultPlayerName returns the name of the user of the machine.
+func defaultPlayerName() string {
+	u, err := user.Current()
+	if err != nil || u.Username == "" {
+		return "player"
+	}
+	return u.Username
+}
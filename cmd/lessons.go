@@ -17,7 +17,7 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strconv"
+	"strings"
 
 	"github.com/boris-lenzinger/repeatit/datamodel"
 	"github.com/boris-lenzinger/repeatit/engine"
@@ -29,6 +29,25 @@ import (
 
 var interactive bool
 
+// sentences requires to be questioned on the sentences of the lessons
+// instead of their vocabulary
+var sentences bool
+
+// cloze requires to fill in the blanks of the sentences of the lessons
+var cloze bool
+
+// withSentences requires to ask the sentences of each lesson after its
+// vocabulary
+var withSentences bool
+
+// scramble requires to rebuild the sentences of the lessons from their
+// shuffled words
+var scramble bool
+
+// forms requires to be questioned on the gender, the plural and the
+// irregular forms of the words of the lessons
+var forms bool
+
 // lessonsCmd represents the lessons command
 var lessonsCmd = &cobra.Command{
 	Use:   "lessons [numbers]",
@@ -38,6 +57,11 @@ The numbers can be dispatched as follow:
   * n:m requires to repeat the lessons n to m
   * n,m requires the lesson n and m
   * you can combine the above syntaxes to generate complex combinations that match your needs
+By default, you are questioned on the vocabulary of the lessons. Use --sentences to be
+questioned on their sentences, --sentences --cloze to fill in the blanks of the sentences
+and --sentences --scramble to rebuild the sentences from their shuffled words. Use
+--with-sentences to be questioned on the sentences of each lesson after its vocabulary.
+Use --forms to be questioned on the gender, the plural and the irregular forms of the words.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
@@ -49,36 +73,37 @@ The numbers can be dispatched as follow:
 		fmt.Printf("[lessons] Is it interactive ? %t\n", params.IsInteractive())
 		fmt.Printf("[lessons] Path to file to handle: %s\n", params.GetLessonsFile())
 
-		// file existence has already been checked by the root command
-		f, err := os.Open(params.GetLessonsFile())
-		if err != nil {
-			tools.Error(err, fmt.Sprintf("failed to parse the lessons file %q", params.GetLessonsFile()))
+		lessonNumbers := toLessonNumbers(lessonsToLearn)
+		topic := loadTopic()
+		if (cloze || scramble) && !sentences {
+			tools.NegativeStatus("Cloze and word order exercises are built from the sentences. Please add --sentences.")
 			os.Exit(1)
 		}
-		lessonsRange, err := parsing.ParseNumberSerie(lessonsToLearn)
-		if err != nil {
-			tools.Error(err, "the arguments passed do not seem to be a list of numbers")
+		if cloze && scramble {
+			tools.NegativeStatus("Please choose between --cloze and --scramble.")
 			os.Exit(1)
 		}
-		lessonNumbers := make([]string, len(lessonsRange))
-		for i := 0; i < len(lessonsRange); i++ {
-			s := strconv.Itoa(lessonsRange[i])
-			if lessonsRange[i] < 10 {
-				s = "0" + s
-			}
-			lessonNumbers[i] = s
-		}
-		parsingParameters := datamodel.TopicParsingParameters{
-			LessonAnnounce:   viper.GetString("announcementForLessons"),
-			SentenceAnnounce: viper.GetString("announcementForSentences"),
-			QaSep:            viper.GetString("qaSep"),
+		if forms && (sentences || withSentences) {
+			tools.NegativeStatus("The forms are asked on the vocabulary. Please remove --sentences and --with-sentences.")
+			os.Exit(1)
 		}
-		topic, err := parsing.ParseTopic(f, parsingParameters)
-		if err != nil {
-			tools.NegativeStatus(fmt.Sprintf("Parsing of %q has failed due to %v", params.GetLessonsFile(), err))
+		exercise := datamodel.ExerciseVocabulary
+		switch {
+		case forms:
+			exercise = datamodel.ExerciseForms
+		case scramble:
+			exercise = datamodel.ExerciseScramble
+		case cloze:
+			exercise = datamodel.ExerciseCloze
+		case sentences:
+			exercise = datamodel.ExerciseSentences
+		case withSentences:
+			exercise = datamodel.ExerciseLessons
 		}
-		tools.Debug(topic.String())
-		qa := topic.BuildVocabularyQuestionsSet(lessonNumbers...)
+		qa := buildQuestionsSet(topic, exercise, lessonNumbers)
+		params.SetExercise(exercise)
+		params.SetListOfSubsections(lessonNumbers...)
+		params.SetLanguages(topic.NativeLanguage, topic.LearnedLanguage)
 		engine.AskQuestions(qa, params)
 	},
 }
@@ -95,4 +120,70 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// lessonsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	lessonsCmd.Flags().BoolVarP(&sentences, "sentences", "", false, "Questions on the sentences of the lessons instead of their vocabulary.")
+	lessonsCmd.Flags().BoolVarP(&withSentences, "with-sentences", "", false, "Questions on the sentences of each lesson after its vocabulary.")
+	lessonsCmd.Flags().BoolVarP(&cloze, "cloze", "", false, `With --sentences, shows the sentences with words blanked out and you type the
+missing words. The words are the ones marked {{c1::word}} in the sentence or, if
+there is no markup, the words of the vocabulary of the lesson found in the sentence.`)
+	lessonsCmd.Flags().BoolVarP(&scramble, "scramble", "", false, `With --sentences, shows the words of the sentences in a random order and you
+rebuild the sentences by typing the words or their numbers. The variants of a
+sentence declared in the file with "||" are accepted.`)
+	lessonsCmd.Flags().BoolVarP(&forms, "forms", "", false, `Asks the gender and the plural of the nouns and the irregular forms of the
+words, such as "gender of Haus?". They are declared in the file after the entry:
+  house;Haus [gender:n] [plural:Häuser]
+  to go;gehen [pos:verb] [forms:ging,gegangen]
+The nouns are colored by gender: blue for masculine, red for feminine and
+green for neuter.`)
+}
+
+// buildQuestionsSet builds the set of questions of the exercise for the
+// lessons passed in parameter. Only the entries matching the --tags filter
+// are kept.
+func buildQuestionsSet(topic datamodel.Topic, exercise string, lessonNumbers []string) datamodel.QuestionsAnswers {
+	return topic.BuildExerciseQuestionsSet(exercise, params.GetRandom(), lessonNumbers...).Filter(params.GetTagFilter()).FilterOnDifficulty(params.GetDifficultyRange())
+}
+
+// checkExercise exits if the name passed in parameter is not one of the
+// exercises.
+func checkExercise(exercise string) {
+	if !datamodel.IsExercise(exercise) {
+		tools.NegativeStatus(fmt.Sprintf("%q is not an exercise. Please choose between %s.", exercise, strings.Join(datamodel.Exercises, ", ")))
+		os.Exit(1)
+	}
+}
+
+// toLessonNumbers transforms the serie of lessons passed on the command line
+// to the list of the IDs of the lessons. Exits if the serie is invalid.
+func toLessonNumbers(serie string) []string {
+	lessonsRange, err := parsing.ParseNumberSerie(serie)
+	if err != nil {
+		tools.Error(err, "the arguments passed do not seem to be a list of numbers")
+		os.Exit(1)
+	}
+	return datamodel.ToLessonIDs(lessonsRange)
+}
+
+// loadTopic parses the lessons file set in the parameters, in the text or
+// the JSON format. Exits if the file cannot be read.
+func loadTopic() datamodel.Topic {
+	// file existence has already been checked by the root command
+	topic, err := parsing.ParseLanguageFile(params.GetLessonsFile(), topicParsingParameters())
+	if err != nil {
+		tools.Error(err, fmt.Sprintf("failed to parse the lessons file %q", params.GetLessonsFile()))
+		os.Exit(1)
+	}
+	tools.Debug(topic.String())
+	return topic
+}
+
+// topicParsingParameters returns the markers of the text format of the
+// lessons file set in the configuration.
+func topicParsingParameters() datamodel.TopicParsingParameters {
+	return datamodel.TopicParsingParameters{
+		LessonAnnounce:      viper.GetString("announcementForLessons"),
+		SentenceAnnounce:    viper.GetString("announcementForSentences"),
+		GrammarAnnounce:     viper.GetString("announcementForGrammar"),
+		ConjugationAnnounce: viper.GetString("announcementForConjugations"),
+		QaSep:               viper.GetString("qaSep"),
+	}
 }
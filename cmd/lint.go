@@ -0,0 +1,56 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/boris-lenzinger/repeatit/parsing"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Checks the lessons file and reports the problems found",
+	Long: `This command parses the lessons file and reports the problems found:
+  * syntax errors
+  * audio clips referencing files that do not exist
+The command exits with a non zero status if a problem is found.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		topic, err := parsing.ParseLanguageFile(params.GetLessonsFile(), topicParsingParameters())
+		if err != nil {
+			tools.Error(err, fmt.Sprintf("failed to parse the lessons file %q", params.GetLessonsFile()))
+			os.Exit(1)
+		}
+		missing := topic.FindMissingMedia(filepath.Dir(params.GetLessonsFile()))
+		for _, m := range missing {
+			tools.NOK(m)
+		}
+		if len(missing) != 0 {
+			tools.NegativeStatus(fmt.Sprintf("%d problem(s) found in %q", len(missing), params.GetLessonsFile()))
+			os.Exit(1)
+		}
+		tools.OK(fmt.Sprintf("No problem found in %q", params.GetLessonsFile()))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
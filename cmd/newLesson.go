@@ -16,32 +16,35 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/parsing"
 	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // newLessonCmd represents the newLesson command
 var newLessonCmd = &cobra.Command{
 	Use:   "lesson",
 	Short: "Creates a new lesson in your lang book",
-	Long:  ``,
+	Long: `This command asks the title of the new lesson and adds the lesson after the
+last one of the lessons file. A JSON file is rewritten with the new lesson. For
+a text file, the header of the lesson is appended to the file:
+  ### Lesson 12 [title:At the restaurant;Im Restaurant]
+`,
 	Run: func(cmd *cobra.Command, args []string) {
 		checkFileOrFail()
-		f, err := os.Open(pathToLessonsFile)
-		if err != nil {
-			tools.Error(err, fmt.Sprintf("error while trying to open %q", pathToLessonsFile))
-			os.Exit(1)
-		}
-		lessons, err := datamodel.LoadLessons(f)
+		topic, err := parsing.ParseLanguageFile(pathToLessonsFile, topicParsingParameters())
 		if err != nil {
 			tools.Error(err, fmt.Sprintf("failed to load the lessons file %q", pathToLessonsFile))
 			os.Exit(1)
 		}
-		f.Close()
-		tools.Info(fmt.Sprintf("Loaded %d lessons", lessons.GetLessonsCount()))
+		tools.Info(fmt.Sprintf("Loaded %d lessons", len(topic.GetAllSubsectionsName())))
 
 		tools.QuestionWithPrompt("Title in the learning language")
 		// Read the input and reject empty value
@@ -50,11 +53,65 @@ var newLessonCmd = &cobra.Command{
 		titleInNativeLanguage := tools.ReadFromStdin(false)
 		tools.Info(fmt.Sprintf("User has entered %q for the original title and %q for the title in its native language", titleInLearningLanguage, titleInNativeLanguage))
 		res := datamodel.Resource{Learning: titleInLearningLanguage, Native: titleInNativeLanguage}
-		fmt.Printf("Loaded res as %+v", res)
-
+		ID := datamodel.FormatLessonID(topic.GetNextLessonNumber())
+		if parsing.IsJSONFile(pathToLessonsFile) {
+			err = addJSONLesson(topic, ID, res)
+		} else {
+			err = addTextLesson(ID, res)
+		}
+		if err != nil {
+			tools.Error(err, fmt.Sprintf("failed to add the lesson to %q", pathToLessonsFile))
+			os.Exit(1)
+		}
+		tools.PositiveStatus(fmt.Sprintf("Lesson %s added to %q", ID, pathToLessonsFile))
 	},
 }
 
+// addJSONLesson adds a lesson with the title passed in parameter to the
+// topic and writes it back to the JSON lessons file. The lessons are
+// written to a temporary file first so the lessons file is left untouched
+// if the writing fails.
+func addJSONLesson(topic datamodel.Topic, ID string, title datamodel.Resource) error {
+	topic.SetLessonTitle(ID, title)
+	l, err := topic.ToLanguage()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(pathToLessonsFile)
+	if err != nil {
+		return errors.Wrapf(err, "error while trying to read %q", pathToLessonsFile)
+	}
+	f, err := ioutil.TempFile(filepath.Dir(pathToLessonsFile), filepath.Base(pathToLessonsFile)+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "error while creating a temporary file next to %q", pathToLessonsFile)
+	}
+	defer os.Remove(f.Name())
+	err = datamodel.SaveLessons(f, l)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error while writing to %q", f.Name())
+	}
+	if err := os.Chmod(f.Name(), info.Mode()); err != nil {
+		return errors.Wrapf(err, "error while setting the permissions of %q", f.Name())
+	}
+	return errors.Wrapf(os.Rename(f.Name(), pathToLessonsFile), "error while replacing %q", pathToLessonsFile)
+}
+
+// addTextLesson appends the header of a lesson with the title passed in
+// parameter to the text lessons file.
+func addTextLesson(ID string, title datamodel.Resource) error {
+	f, err := os.OpenFile(pathToLessonsFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "error while trying to open %q", pathToLessonsFile)
+	}
+	defer f.Close()
+	sep := viper.GetString("qaSep")
+	_, err = fmt.Fprintf(f, "\n%s%s [title:%s%s%s]\n", viper.GetString("announcementForLessons"), ID, title.Native, sep, title.Learning)
+	return errors.Wrapf(err, "error while writing to %q", pathToLessonsFile)
+}
+
 func init() {
 	newCmd.AddCommand(newLessonCmd)
 
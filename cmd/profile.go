@@ -0,0 +1,65 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manages the profiles of the learners sharing the lessons files",
+	Long: `Each profile keeps its own results, saved session and settings so several
+learners can study the same lessons files. The settings of a profile are in
+the file settings.yaml of its folder and override the ones of the
+configuration file. The profile is chosen with --profile and remembered for
+the next runs. The profile default is used until another one is chosen.
+`,
+	// the profiles do not need a lessons file: the checks of the root
+	// command are skipped
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// profileCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// profileCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
+
+// getProfilesDataDir returns the data directory holding the profiles or
+// exits if there is none.
+func getProfilesDataDir() string {
+	dataDir := getDataDir()
+	if dataDir == "" {
+		tools.NegativeStatus(fmt.Sprintf("Profiles cannot be used without a data directory. Please set %s in your $HOME/.repeatit.yaml", keyDataDir))
+		os.Exit(1)
+	}
+	return dataDir
+}
@@ -0,0 +1,55 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// profileCreateCmd represents the profile create command
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Creates a profile with an empty settings file.",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			tools.NegativeStatus("Usage: repeatit profile create <name>")
+			os.Exit(1)
+		}
+		if err := datamodel.CreateProfile(getProfilesDataDir(), args[0]); err != nil {
+			tools.Error(err, "failed to create the profile")
+			os.Exit(1)
+		}
+		tools.PositiveStatus(fmt.Sprintf("Profile %q created. Use it with --profile %s", args[0], args[0]))
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileCreateCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// profileCreateCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// profileCreateCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,55 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// profileDeleteCmd represents the profile delete command
+var profileDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Deletes a profile with all its results. The default profile cannot be deleted.",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			tools.NegativeStatus("Usage: repeatit profile delete <name>")
+			os.Exit(1)
+		}
+		if err := datamodel.DeleteProfile(getProfilesDataDir(), args[0]); err != nil {
+			tools.Error(err, "failed to delete the profile")
+			os.Exit(1)
+		}
+		tools.PositiveStatus(fmt.Sprintf("Profile %q deleted.", args[0]))
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileDeleteCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// profileDeleteCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// profileDeleteCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,63 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// profileListCmd represents the profile list command
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the profiles. The last one used is marked with a star.",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		dataDir := getProfilesDataDir()
+		profiles, err := datamodel.ListProfiles(dataDir)
+		if err != nil {
+			tools.Error(err, "failed to list the profiles")
+			os.Exit(1)
+		}
+		last, err := datamodel.LoadLastProfile(dataDir)
+		if err != nil {
+			tools.Warning(fmt.Sprintf("%v", err))
+		}
+		for _, name := range profiles {
+			marker := " "
+			if name == last {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+		}
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// profileListCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// profileListCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
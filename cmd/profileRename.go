@@ -0,0 +1,55 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// profileRenameCmd represents the profile rename command
+var profileRenameCmd = &cobra.Command{
+	Use:   "rename <name> <new name>",
+	Short: "Renames a profile, keeping its results and its settings.",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			tools.NegativeStatus("Usage: repeatit profile rename <name> <new name>")
+			os.Exit(1)
+		}
+		if err := datamodel.RenameProfile(getProfilesDataDir(), args[0], args[1]); err != nil {
+			tools.Error(err, "failed to rename the profile")
+			os.Exit(1)
+		}
+		tools.PositiveStatus(fmt.Sprintf("Profile %q renamed to %q.", args[0], args[1]))
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileRenameCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// profileRenameCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// profileRenameCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
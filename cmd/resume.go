@@ -0,0 +1,99 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// discard requires to delete the saved session instead of resuming it
+var discard bool
+
+// resumeCmd represents the resume command
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resumes the last interrupted session",
+	Long: `A session is saved in the data directory when it is interrupted with Ctrl-C or
+when you type :quit instead of an answer in interactive mode. This command goes
+on with the saved session from the question that was interrupted: same lessons,
+same mode, same seed and same limits.
+The session cannot be resumed if the lessons file has changed since. Use
+--discard to delete the saved session.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := params.GetSnapshotFile()
+		if path == "" {
+			tools.NegativeStatus("No data directory is available: no session can be resumed.")
+			os.Exit(1)
+		}
+		if discard {
+			if err := datamodel.RemoveSnapshot(path); err != nil {
+				tools.Error(err, "failed to discard the saved session")
+				os.Exit(1)
+			}
+			tools.OK("The saved session has been discarded.")
+			return
+		}
+		snapshot, err := datamodel.LoadSnapshot(path)
+		if err != nil {
+			tools.Error(err, "failed to load the saved session")
+			os.Exit(1)
+		}
+		if snapshot == nil {
+			tools.NegativeStatus("There is no session to resume.")
+			os.Exit(1)
+		}
+		stale, err := snapshot.IsStale()
+		if err != nil {
+			tools.Error(err, fmt.Sprintf("failed to check the lessons file %q of the saved session", snapshot.LessonsFile))
+			os.Exit(1)
+		}
+		if stale {
+			tools.NegativeStatus(fmt.Sprintf("The lessons file %q has changed since the session was saved on %s. Use --discard and start a new session.",
+				snapshot.LessonsFile, snapshot.SavedAt.Format("2006-01-02 15:04")))
+			os.Exit(1)
+		}
+
+		params.ResumeFrom(snapshot)
+		topic := loadTopic()
+		qa := buildQuestionsSet(topic, snapshot.Exercise, snapshot.Subsections)
+		params.SetLanguages(topic.NativeLanguage, topic.LearnedLanguage)
+		if err := engine.AskQuestions(qa, params); err != nil {
+			tools.Error(err, "failed to resume the session")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// resumeCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// resumeCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	resumeCmd.Flags().BoolVarP(&discard, "discard", "", false, "Deletes the saved session instead of resuming it.")
+}
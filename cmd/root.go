@@ -17,18 +17,26 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/boris-lenzinger/repeatit/datamodel"
 	"github.com/boris-lenzinger/repeatit/engine"
 	"github.com/boris-lenzinger/repeatit/parsing"
 	"github.com/boris-lenzinger/repeatit/tools"
 	homedir "github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const (
 	keySourceFile = "sourceFile"
+	keyDataDir    = "dataDir"
+
+	// defaultDataDir is the name of the folder, in the home directory of
+	// the user, where the results of the sessions are stored.
+	defaultDataDir = ".repeatit.d"
 )
 
 // global string that stores the path to the configuration file in
@@ -43,6 +51,54 @@ var Debug bool
 // sentences to learn
 var pathToLessonsFile string
 
+// weighted requires to ask more often the items missed or answered slowly
+var weighted bool
+
+// explain requires to display the weight of the items in weighted mode
+var explain bool
+
+// seed initializes the random source so a session can be replayed
+var seed int64
+
+// maxDuration stops the session once elapsed
+var maxDuration time.Duration
+
+// maxQuestions stops the session once this number of questions is asked
+var maxQuestions int
+
+// mixed requires that each prompt picks randomly its direction
+var mixed bool
+
+// recognitionRatio is the share of recognition prompts in mixed mode
+var recognitionRatio float64
+
+// pause is a fixed pause between the question and the answer
+var pause time.Duration
+
+// speakAloud requires to speak the questions and the answers
+var speakAloud bool
+
+// listeningOnly requires to hide the text of the questions
+var listeningOnly bool
+
+// scriptFile is a file of interpreter commands to run before the prompt
+var scriptFile string
+
+// controlSocket is the Unix socket receiving the commands driving a session
+var controlSocket string
+
+// eventsAddress is the address streaming the events of a session
+var eventsAddress string
+
+// profile is the name of the learner whose results and settings are used
+var profile string
+
+// tags selects the entries of the lessons on their tags
+var tags string
+
+// difficulty selects the entries of the lessons on their difficulty
+var difficulty string
+
 // params is a global variable of the command interpreter.
 //
 var params datamodel.InterrogationParameters
@@ -61,10 +117,59 @@ var rootCmd = &cobra.Command{
 			pathToLessonsFile = viper.GetString(keySourceFile)
 		}
 		tools.Debug(fmt.Sprintf("[root] Arguments received: %+v\n", args))
+		// the settings of the profile must be applied before the
+		// parameters read them
+		profileDir := useProfile(cmd)
 		params = datamodel.NewInterrogationParameters()
 		if interactive {
 			params.SetInteractive()
 		}
+		if weighted {
+			params.SetWeightedMode()
+		}
+		if explain {
+			params.SetExplainMode()
+		}
+		if speakAloud {
+			params.EnableSpeech()
+		}
+		if listeningOnly {
+			params.SetListeningOnlyMode()
+		}
+		if params.GetSpeechSettings().Enabled && !params.GetSpeechSettings().IsConfigured() {
+			tools.NegativeStatus(fmt.Sprintf("--speak and --listen need the command speaking the text. Set it in your $HOME/.repeatit.yaml with the key %q, such as: espeak-ng -v {lang} {text}", "speech.command"))
+			os.Exit(1)
+		}
+		if cmd.Flags().Changed("pause") {
+			params.SetPauseTime(pause)
+		}
+		if cmd.Flags().Changed("seed") {
+			params.SetSeed(seed)
+		}
+		if mixed {
+			if recognitionRatio < 0 || recognitionRatio > 1 {
+				tools.NegativeStatus(fmt.Sprintf("The ratio of recognition prompts must be between 0 and 1. Received %.2f", recognitionRatio))
+				os.Exit(1)
+			}
+			params.SetMixedDirectionMode(recognitionRatio)
+		}
+		params.SetMaxDuration(maxDuration)
+		params.SetMaxQuestions(maxQuestions)
+		params.SetControlSocket(controlSocket)
+		params.SetEventsAddress(eventsAddress)
+		tagFilter, err := datamodel.ParseTagFilter(tags)
+		if err != nil {
+			tools.NegativeStatus(fmt.Sprintf("Invalid --tags filter: %v", err))
+			os.Exit(1)
+		}
+		params.SetTagFilter(tagFilter)
+		difficultyRange, err := datamodel.ParseDifficultyRange(difficulty)
+		if err != nil {
+			tools.NegativeStatus(fmt.Sprintf("Invalid --difficulty range: %v", err))
+			os.Exit(1)
+		}
+		params.SetDifficultyRange(difficultyRange)
+		params.SetDataDir(profileDir)
 		exists, err := tools.FileExists(pathToLessonsFile)
 		if err != nil {
 			tools.Error(err, fmt.Sprintf("error while checking if lessons file %q exists", pathToLessonsFile))
@@ -83,17 +188,15 @@ var rootCmd = &cobra.Command{
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	Run: func(cmd *cobra.Command, args []string) {
-		parsingParameters := datamodel.TopicParsingParameters{
-			LessonAnnounce:   viper.GetString("announcementForLessons"),
-			SentenceAnnounce: viper.GetString("announcementForSentences"),
-			QaSep:            viper.GetString("qaSep"),
-		}
-		t, err := parsing.ParseLanguageFile(pathToLessonsFile, parsingParameters)
+		t, err := parsing.ParseLanguageFile(pathToLessonsFile, topicParsingParameters())
 		if err != nil {
 			tools.NegativeStatus(fmt.Sprintf("failed to parse file %q due to %v", pathToLessonsFile, err))
 			os.Exit(0)
 		}
-		engine.StartEngine(t)
+		if err := engine.StartEngine(t, params, scriptFile); err != nil {
+			tools.NegativeStatus(fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
 		tools.Debug("[root] Calling PersistentPostRun")
@@ -125,9 +228,66 @@ This allows you to be in a learning way or enforcing your knowledge. It lets you
 memory and answer when you feel ready.
 If this flag is not set, you will not have to press the Return key and you
 simply have to wait for a  given time. Questions and answers flow with a time
-interval between them. See -t for details about time.`)
+interval between them. See -t for details about time.
+Type :quit instead of an answer to stop the session and save it: see the resume command.`)
 	rootCmd.PersistentFlags().StringVarP(&pathToLessonsFile, "lessons", "", "", "the path to the file containing the lessons.")
 	rootCmd.PersistentFlags().BoolVarP(&Debug, "debug", "", false, "Enables debug mode on the client.")
+	rootCmd.PersistentFlags().BoolVarP(&weighted, "weighted", "", false, `If set, the questions you missed recently or answered slowly are asked
+more often. Results are recorded when you type your answer in interactive mode.`)
+	rootCmd.PersistentFlags().Int64VarP(&seed, "seed", "", 0, `Seeds the random source used to draw the questions. The seed is displayed at
+the start of each session: reuse it to get the exact same order of questions.`)
+	rootCmd.PersistentFlags().DurationVarP(&pause, "pause", "", datamodel.DefaultInterrogationPause, `Fixed pause between the question and the answer in unattended mode. It
+overrides the adaptive pause that can be configured in $HOME/.repeatit.yaml:
+  pause:
+    adaptive: true
+    vocabulary:
+      base: 1s
+      perCharacter: 50ms
+    sentences:
+      base: 1s
+      perWord: 400ms`)
+	rootCmd.PersistentFlags().BoolVarP(&speakAloud, "speak", "", false, `Speaks the questions and the answers with the command configured in
+$HOME/.repeatit.yaml. {lang} and {text} are replaced by the language and the text:
+  speech:
+    command: espeak-ng -v {lang} {text}
+    voices:
+      french: fr`)
+	rootCmd.PersistentFlags().BoolVarP(&listeningOnly, "listen", "", false, "Listening only mode: the questions are spoken but their text is hidden. Implies --speak.")
+	rootCmd.PersistentFlags().BoolVarP(&mixed, "mixed", "", false, `If set, each prompt picks randomly its direction: the native word to translate
+into the learnt language (production) or the learnt word to translate into your
+native language (recognition). Results are tracked separately for each direction.`)
+	rootCmd.PersistentFlags().Float64VarP(&recognitionRatio, "recognition-ratio", "", datamodel.DefaultRecognitionRatio, "Share of the prompts asked in the recognition direction when --mixed is set (between 0 and 1).")
+	rootCmd.PersistentFlags().DurationVarP(&maxDuration, "duration", "", 0, `Stops the session once this duration is elapsed (10m for instance). A summary of
+what was covered is displayed. Combines with the number of loops: the first limit reached wins.`)
+	rootCmd.PersistentFlags().IntVarP(&maxQuestions, "max-questions", "", 0, `Stops the session once this number of questions is asked. A summary of what was
+covered is displayed. Combines with the number of loops: the first limit reached wins.`)
+	rootCmd.Flags().StringVarP(&scriptFile, "script", "", "", `File of interpreter commands to run before the prompt is displayed (see the
+run command for the syntax). The interpreter exits with a non zero status if
+a command of the file fails.`)
+	rootCmd.PersistentFlags().StringVarP(&controlSocket, "control-socket", "", "", `Path of a Unix socket on which the session accepts commands, one per line:
+  * next or reveal: shows the answer and goes on with the next question, as
+    Return does in interactive mode. In unattended mode, it cuts the pause short
+  * grade <1-4>: grades yourself (1 is again, 4 is easy) then shows the answer
+  * quit: stops the session and saves it, as :quit does
+Each command is acknowledged with a line: ok, or error followed by the reason.
+Scripts, a foot pedal or a second terminal can drive the session this way:
+  echo next | nc -U /tmp/repeatit.sock`)
+	rootCmd.PersistentFlags().StringVarP(&eventsAddress, "events-addr", "", "", `Address on which the events of the session are streamed as server-sent events,
+for the overlays and second screens: question-asked, answer-graded,
+answer-revealed, loop-started and session-ended. With localhost:8081, they are
+read on http://localhost:8081/events.`)
+	rootCmd.PersistentFlags().StringVarP(&profile, "profile", "", "", `Name of the learner: each profile has its own results, saved session and
+settings. The last profile used is remembered, so the flag is only needed to
+switch. See the profile command to create the profiles.`)
+	rootCmd.PersistentFlags().StringVarP(&tags, "tags", "", "", `Only asks the entries with the tags, whatever their lesson. The tags prefixed
+with ! exclude the entries: --tags verb,!irregular asks the verbs that are not
+irregular. The tags are set in the lessons file with [tags:verb,irregular] on an
+entry or on the line announcing a lesson, for all its entries.`)
+	rootCmd.PersistentFlags().StringVarP(&difficulty, "difficulty", "", "", `Only asks the entries whose difficulty is in the range: --difficulty 1:3 asks
+the entries rated from 1 to 3, --difficulty 5 the hardest ones. The difficulty
+is set in the lessons file with [difficulty:3]. The entries without difficulty
+are left out. In weighted mode, the hardest entries come back more often.`)
+	rootCmd.PersistentFlags().BoolVarP(&explain, "explain", "", false, "Displays the weight of each question before a weighted session starts.")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -165,6 +325,94 @@ func initConfig() {
 	}
 }
 
+// getDataDir returns the folder where the results of the sessions are
+// stored. It can be set in the configuration file with the key dataDir.
+func getDataDir() string {
+	dir := viper.GetString(keyDataDir)
+	if dir != "" {
+		return dir
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		tools.Warning(fmt.Sprintf("cannot find home directory, results will not be saved: %v", err))
+		return ""
+	}
+	return filepath.Join(home, defaultDataDir)
+}
+
+// useProfile selects the profile set with --profile, or else the last one
+// used, and remembers it for the next runs. Its settings are merged on top
+// of the configuration file. It returns the directory where the results of
+// the profile are stored.
+func useProfile(cmd *cobra.Command) string {
+	dataDir := getDataDir()
+	if dataDir == "" {
+		if cmd.Flags().Changed("profile") {
+			tools.NegativeStatus("Profiles cannot be used without a data directory. Please set dataDir in your $HOME/.repeatit.yaml")
+			os.Exit(1)
+		}
+		return ""
+	}
+	name := profile
+	if !cmd.Flags().Changed("profile") {
+		var err error
+		name, err = datamodel.LoadLastProfile(dataDir)
+		if err != nil {
+			tools.Warning(fmt.Sprintf("using the default profile: %v", err))
+		}
+	} else if err := datamodel.CheckProfileName(name); err != nil {
+		// the name is used as a folder: it must not lead out of the
+		// folder of the profiles
+		tools.NegativeStatus(fmt.Sprintf("Invalid --profile: %v", err))
+		os.Exit(1)
+	}
+	exists, err := datamodel.ProfileExists(dataDir, name)
+	if err != nil {
+		tools.Error(err, fmt.Sprintf("failed to check if profile %q exists", name))
+		os.Exit(1)
+	}
+	if !exists {
+		if cmd.Flags().Changed("profile") {
+			tools.NegativeStatus(fmt.Sprintf("Profile %q does not exist. Create it with: repeatit profile create %s", name, name))
+			os.Exit(1)
+		}
+		// the last profile used was removed by hand
+		tools.Warning(fmt.Sprintf("profile %q does not exist anymore: using the default profile", name))
+		name = datamodel.DefaultProfile
+	}
+	if err := applyProfileSettings(datamodel.GetProfileSettingsFile(dataDir, name)); err != nil {
+		tools.Error(err, fmt.Sprintf("failed to apply the settings of profile %q", name))
+		os.Exit(1)
+	}
+	if err := datamodel.SaveLastProfile(dataDir, name); err != nil {
+		tools.Warning(fmt.Sprintf("the profile %q will not be remembered: %v", name, err))
+	}
+	tools.Debug(fmt.Sprintf("[root] Using profile %q", name))
+	return datamodel.GetProfileDir(dataDir, name)
+}
+
+// applyProfileSettings merges the settings of a profile on top of the ones
+// of the configuration file. Nothing is done if the profile has no
+// settings file.
+func applyProfileSettings(path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to open the settings %q", path)
+	}
+	defer f.Close()
+	viper.SetConfigType("yaml")
+	if err := viper.MergeConfig(f); err != nil {
+		return errors.Wrapf(err, "failed to read the settings %q", path)
+	}
+	return nil
+}
+
 func checkFileOrFail() {
 	exists, err := tools.FileExists(pathToLessonsFile)
 	if err != nil {
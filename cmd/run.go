@@ -0,0 +1,74 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run <routine.rit>",
+	Short: "Runs the interpreter commands written in a file",
+	Long: `This command runs, in sequence and with no prompt, the commands of the
+interpreter written in a file. This makes it possible to keep study routines
+in files:
+  # warm up on the first lessons, then the hard ones
+  let week = 1:5
+  select $week; set reverse on
+  select 3,7
+Each line holds one or more commands separated by ";". The lines starting
+with "#" are comments. Variables are defined with let and used with $name or
+${name}. The command stops at the first command that fails, reports its line
+and exits with a non zero status.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			tools.NegativeStatus("Please supply the file of commands to run.")
+			os.Exit(1)
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			tools.Error(err, fmt.Sprintf("failed to open the script %q", args[0]))
+			os.Exit(1)
+		}
+		defer f.Close()
+		topic := loadTopic()
+		err = engine.RunScript(topic, params, f, args[0])
+		if err != nil {
+			tools.NegativeStatus(fmt.Sprintf("%v", err))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// runCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// runCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
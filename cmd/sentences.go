@@ -0,0 +1,67 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// sentencesCmd represents the sentences command
+var sentencesCmd = &cobra.Command{
+	Use:   "sentences [numbers]",
+	Short: "Requires repetition of the sentences of the lessons set on the command line",
+	Long: `This commands requires to repeat the sentences of a series of lessons.
+The numbers can be dispatched as follow:
+  * n:m requires to repeat the sentences of the lessons n to m
+  * n,m requires the sentences of the lessons n and m
+  * you can combine the above syntaxes to generate complex combinations that match your needs
+It is a shortcut for lessons --sentences.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			tools.NegativeStatus("Please supply lessons number. Check the syntax of the command if you don't know how to set lessons number.")
+			os.Exit(1)
+		}
+		tools.Debug(fmt.Sprintf("[sentences] Path to file to handle: %s", params.GetLessonsFile()))
+
+		lessonNumbers := toLessonNumbers(args[0])
+		topic := loadTopic()
+		qa := buildQuestionsSet(topic, datamodel.ExerciseSentences, lessonNumbers)
+		params.SetExercise(datamodel.ExerciseSentences)
+		params.SetListOfSubsections(lessonNumbers...)
+		params.SetLanguages(topic.NativeLanguage, topic.LearnedLanguage)
+		engine.AskQuestions(qa, params)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sentencesCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// sentencesCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// sentencesCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,79 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/server"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// addr is the address the server listens on
+var addr string
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Studies the lessons in a browser through a local web server",
+	Long: `This command starts a web server on the lessons file. Open the address in a
+browser to study the lessons with a small web page. The same server exposes
+a JSON API:
+  * GET    /api/lessons                 lists the lessons
+  * GET    /api/sessions                lists the running sessions
+  * POST   /api/sessions                starts a session. The body gives the
+                                        lessons ({"lessons": "1:3"}) and
+                                        optionally exercise, mode, reverse,
+                                        limit, seed and tags
+  * POST   /api/sessions/{id}/next      draws the next card
+  * POST   /api/sessions/{id}/answer    grades an attempt ({"attempt": "..."})
+  * POST   /api/sessions/{id}/reveal    gives the answer
+  * GET    /api/sessions/{id}/stats     gives the results of the session
+  * GET    /api/sessions/{id}/events    streams the events of the session as
+                                        server-sent events, for an overlay
+                                        or a second screen
+  * DELETE /api/sessions/{id}           ends the session
+A finished session is forgotten after 10 minutes and a session nobody uses
+after 2 hours. Their results are saved.
+The settings of the command line (mode, limit, data directory...) are the
+default settings of the sessions. The server listens on the local machine
+only unless another address is set.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		topic := loadTopic()
+		tools.PositiveStatus(fmt.Sprintf("Serving the lessons on http://%s", addr))
+		if err := server.New(topic, params).ListenAndServe(addr); err != nil {
+			tools.Error(err, "the server has failed")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// serveCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// serveCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	serveCmd.Flags().StringVarP(&addr, "addr", "", "127.0.0.1:8080", "Address the server listens on.")
+}
@@ -0,0 +1,79 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/cobra"
+)
+
+// showGrammarCmd represents the showGrammar command
+var showGrammarCmd = &cobra.Command{
+	Use:   "grammar [numbers]",
+	Short: "Shows the grammar rules of the lessons then drills their exercises",
+	Long: `This command displays the grammar rules of the lessons selected as for the
+lessons command: their title, their explanation and their examples. You are
+then questioned on their exercises. The rules are written in the lessons file
+after a "### Grammar Lesson" line:
+  ### Grammar Lesson 01
+  ## Word order after weil
+  After weil, the conjugated verb goes to the end of the clause.
+  > I stay because it rains.;Ich bleibe, weil es regnet.
+  ? Ich bleibe, weil es ____. (regnen);regnet
+  = Er kommt. (weil);weil er kommt
+The lines starting with ">" are examples, the ones starting with "?" are
+sentences to fill in and the ones starting with "=" sentences to transform.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			tools.NegativeStatus("Please supply lessons number. Check the syntax of the command if you don't know how to set lessons number.")
+			os.Exit(1)
+		}
+		lessonNumbers := toLessonNumbers(args[0])
+		topic := loadTopic()
+		if engine.WriteGrammar(os.Stdout, topic, lessonNumbers) == 0 {
+			tools.Warning(fmt.Sprintf("There is no grammar exercise in the lessons %s.", args[0]))
+			return
+		}
+		qa := buildQuestionsSet(topic, datamodel.ExerciseGrammar, lessonNumbers)
+		params.SetExercise(datamodel.ExerciseGrammar)
+		params.SetListOfSubsections(lessonNumbers...)
+		params.SetLanguages(topic.NativeLanguage, topic.LearnedLanguage)
+		if err := engine.AskQuestions(qa, params); err != nil {
+			tools.Error(err, "failed to drill the grammar exercises")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	showCmd.AddCommand(showGrammarCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// showGrammarCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// showGrammarCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
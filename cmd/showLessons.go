@@ -15,8 +15,10 @@
 package cmd
 
 import (
-	"fmt"
+	"os"
 
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/tools"
 	"github.com/spf13/cobra"
 )
 
@@ -24,9 +26,19 @@ import (
 var showLessonsCmd = &cobra.Command{
 	Use:   "lessons",
 	Short: "Show the available lessons listed in a file. The count of words per lesson is displayed.",
-	Long:  ``,
+	Long: `This command displays the book of the lessons file, if any, and the table of
+its lessons: their title and their number of words, sentences, grammar rules
+and conjugation tables. The title of a lesson is set in the text format on its
+header line:
+  ### Lesson 01 [title:At the restaurant;Im Restaurant]
+`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("showLessons called")
+		topic := loadTopic()
+		topic.ShowSummary()
+		if err := engine.WriteLessons(os.Stdout, topic); err != nil {
+			tools.Error(err, "failed to display the lessons")
+			os.Exit(1)
+		}
 	},
 }
 
@@ -15,18 +15,30 @@
 package cmd
 
 import (
-	"fmt"
+	"os"
 
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/tools"
 	"github.com/spf13/cobra"
 )
 
 // showSentencesCmd represents the showSentences command
 var showSentencesCmd = &cobra.Command{
-	Use:   "sentences",
+	Use:   "sentences [numbers]",
 	Short: "Show the details about sentences for each section",
-	Long:  ``,
+	Long: `This command displays the sentences of the lessons selected as for the lessons
+command, with their translation. All the lessons are displayed if none is
+selected.
+`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("showSentences called")
+		topic := loadTopic()
+		lessonNumbers := topic.GetAllSubsectionsName()
+		if len(args) > 0 {
+			lessonNumbers = toLessonNumbers(args[0])
+		}
+		if engine.WriteSentences(os.Stdout, topic, lessonNumbers) == 0 {
+			tools.Warning("There is no sentence in the selected lessons.")
+		}
 	},
 }
 
@@ -0,0 +1,86 @@
+// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/boris-lenzinger/repeatit/tui"
+	"github.com/spf13/cobra"
+)
+
+// exercise is the kind of questions of the full screen session
+var exercise string
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui [numbers]",
+	Short: "Studies the lessons set on the command line in a full screen view",
+	Long: `This command displays the questions of the lessons one card at a time, full
+screen, with the statistics of the session on the side. The lessons are
+selected as for the lessons command. The keys are:
+  * space or return: reveals the answer, then shows the next card
+  * 1 to 4: grades yourself (again, hard, good, easy) and shows the next card.
+    1 counts as a wrong answer, the others as a correct one
+  * s: skips the card
+  * h: gives a hint, each new hint reveals one more letter of each word
+  * f: flags the card to review it later
+  * q or escape: leaves. The session is saved: see the resume command
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			tools.NegativeStatus("Please supply lessons number. Check the syntax of the command if you don't know how to set lessons number.")
+			os.Exit(1)
+		}
+		checkExercise(exercise)
+		lessonNumbers := toLessonNumbers(args[0])
+		topic := loadTopic()
+		params.SetExercise(exercise)
+		params.SetListOfSubsections(lessonNumbers...)
+		params.SetLanguages(topic.NativeLanguage, topic.LearnedLanguage)
+		session, err := engine.NewSession(topic, params, nil)
+		if err != nil {
+			tools.Error(err, "failed to start the session")
+			os.Exit(1)
+		}
+		err = tui.Run(session)
+		if closeErr := session.Suspend(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			tools.Error(err, "the session has failed")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// tuiCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// tuiCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	tuiCmd.Flags().StringVarP(&exercise, "exercise", "", datamodel.ExerciseVocabulary, `Kind of questions: vocabulary, sentences, lessons (the sentences of each
+lesson after its vocabulary), cloze, scramble, forms, grammar or conjugation.`)
+}
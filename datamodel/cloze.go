@@ -0,0 +1,136 @@
+package datamodel
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ClozeBlank is the text that replaces a deleted word in a cloze exercise.
+const ClozeBlank = "____"
+
+// ClozeSeparator separates, in the answer of a cloze exercise, the words
+// deleted by the blanks.
+const ClozeSeparator = " ... "
+
+// clozeMarkup matches a cloze deletion: {{c1::word}} or {{c1::word::hint}}.
+var clozeMarkup = regexp.MustCompile(`\{\{c([0-9]+)::(.*?)(?:::(.*?))?\}\}`)
+
+// HasClozeMarkup tells if the text contains at least one cloze deletion.
+func HasClozeMarkup(text string) bool {
+	return clozeMarkup.MatchString(text)
+}
+
+// StripClozeMarkup returns the text with the cloze deletions replaced by
+// the words they delete: "Le chat {{c1::dort}}" becomes "Le chat dort".
+func StripClozeMarkup(text string) string {
+	return clozeMarkup.ReplaceAllString(text, "$2")
+}
+
+// Cloze is a sentence with one or more words blanked out, and the words
+// that were deleted.
+type Cloze struct {
+	// Text is the sentence with the blanks
+	Text string
+	// Deleted is the list of the deleted words, in order of appearance
+	Deleted []string
+}
+
+// ParseClozes builds a cloze exercise for each of the deletion numbers
+// found in the text. The deletions with the same number are blanked out
+// together, the others are shown as plain words. A hint, if any, is shown
+// in the blank.
+func ParseClozes(text string) []Cloze {
+	numbers := map[int]bool{}
+	for _, m := range clozeMarkup.FindAllStringSubmatch(text, -1) {
+		n, _ := strconv.Atoi(m[1])
+		numbers[n] = true
+	}
+	sorted := make([]int, 0, len(numbers))
+	for n := range numbers {
+		sorted = append(sorted, n)
+	}
+	sort.Ints(sorted)
+
+	clozes := make([]Cloze, 0, len(sorted))
+	for _, n := range sorted {
+		c := Cloze{}
+		c.Text = clozeMarkup.ReplaceAllStringFunc(text, func(s string) string {
+			m := clozeMarkup.FindStringSubmatch(s)
+			if m[1] != strconv.Itoa(n) {
+				return m[2]
+			}
+			c.Deleted = append(c.Deleted, m[2])
+			if m[3] != "" {
+				return fmt.Sprintf("%s [%s]", ClozeBlank, m[3])
+			}
+			return ClozeBlank
+		})
+		clozes = append(clozes, c)
+	}
+	return clozes
+}
+
+// BlankWord builds a cloze exercise by deleting each occurrence of a word
+// in a sentence. The comparison ignores the case and only whole words are
+// deleted. The boolean is false if the word is not in the sentence.
+func BlankWord(sentence, word string) (Cloze, bool) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return Cloze{}, false
+	}
+	re, err := regexp.Compile(`(?i)(^|[^\pL\pN])(` + regexp.QuoteMeta(word) + `)($|[^\pL\pN])`)
+	if err != nil {
+		return Cloze{}, false
+	}
+	found := re.FindStringSubmatch(sentence)
+	if found == nil {
+		return Cloze{}, false
+	}
+	return Cloze{
+		Text:    re.ReplaceAllString(sentence, "${1}"+ClozeBlank+"${3}"),
+		Deleted: []string{found[2]},
+	}, true
+}
+
+// BuildClozeQuestionsSet creates a set of cloze exercises from the
+// sentences of the lessons passed in parameter. If the sentence has a
+// cloze markup, the exercises are built from it. Else the words of the
+// vocabulary of the same lesson found in the sentence are blanked out, one
+// exercise per word. The question shows the sentence with its blanks and
+// its translation, the answer is the list of the deleted words.
+// If no lesson is supplied, all the sentences are used.
+func (topic Topic) BuildClozeQuestionsSet(ids ...string) QuestionsAnswers {
+	qa := NewQA()
+	var subsections = ids
+	if len(subsections) == 0 {
+		subsections = topic.GetSentencesSubsectionsName()
+		sort.Strings(subsections)
+	}
+	for _, ID := range subsections {
+		sentences := topic.GetSentencesSubsection(ID)
+		vocabulary := topic.GetVocabularySubsection(ID)
+		for i := 0; i < sentences.GetCount(); i++ {
+			var clozes []Cloze
+			if marked := sentences.GetCloze(i); marked != "" {
+				clozes = ParseClozes(marked)
+			} else {
+				for j := 0; j < vocabulary.GetCount(); j++ {
+					if c, ok := BlankWord(sentences.GetAnswer(i), vocabulary.GetAnswer(j)); ok {
+						clozes = append(clozes, c)
+					}
+				}
+			}
+			for _, c := range clozes {
+				question := fmt.Sprintf("%s (%s)", c.Text, sentences.GetQuestion(i))
+				qa.AddEntryOfKind(question, strings.Join(c.Deleted, ClozeSeparator), Sentence)
+				qa.SetMedia(qa.GetCount()-1, sentences.GetMedia(i))
+				qa.SetTags(qa.GetCount()-1, sentences.GetTags(i))
+				qa.SetDifficulty(qa.GetCount()-1, sentences.GetDifficulty(i))
+			}
+		}
+	}
+	return qa
+}
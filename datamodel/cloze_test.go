@@ -0,0 +1,57 @@
+package datamodel
+
+import "testing"
+
+// TestParseClozes checks that a cloze exercise is built for each deletion
+// number and that the other deletions are shown as plain words.
+func TestParseClozes(t *testing.T) {
+	clozes := ParseClozes("Le {{c1::chat}} {{c2::dort::verb}} sur le {{c1::tapis}}.")
+	if len(clozes) != 2 {
+		t.Fatalf("Expected 2 cloze exercises but got %d", len(clozes))
+	}
+	expected := []Cloze{
+		{Text: "Le ____ dort sur le ____.", Deleted: []string{"chat", "tapis"}},
+		{Text: "Le chat ____ [verb] sur le tapis.", Deleted: []string{"dort"}},
+	}
+	for i, e := range expected {
+		if clozes[i].Text != e.Text {
+			t.Errorf("Expected text %q but got %q", e.Text, clozes[i].Text)
+		}
+		if len(clozes[i].Deleted) != len(e.Deleted) {
+			t.Fatalf("Expected deleted words %v but got %v", e.Deleted, clozes[i].Deleted)
+		}
+		for j := range e.Deleted {
+			if clozes[i].Deleted[j] != e.Deleted[j] {
+				t.Errorf("Expected deleted words %v but got %v", e.Deleted, clozes[i].Deleted)
+			}
+		}
+	}
+	if s := StripClozeMarkup("Le {{c1::chat}} {{c2::dort::verb}}."); s != "Le chat dort." {
+		t.Errorf("Expected the markup to be stripped but got %q", s)
+	}
+}
+
+// TestBuildClozeQuestionsSet checks that, without markup, the words of the
+// vocabulary of the lesson are blanked out in the sentences.
+func TestBuildClozeQuestionsSet(t *testing.T) {
+	vocabulary := NewQA()
+	vocabulary.AddEntry("cat", "chat")
+	vocabulary.AddEntry("dog", "chien")
+	sentences := NewQA()
+	sentences.AddEntryOfKind("The cat sleeps.", "Le chat dort.", Sentence)
+	sentences.AddEntryOfKind("Nothing here.", "Rien ici.", Sentence)
+	topic := NewTopic()
+	topic.SetVocabularySubsection("01", vocabulary)
+	topic.SetSentencesSubsection("01", sentences)
+
+	qa := topic.BuildClozeQuestionsSet("01")
+	if qa.GetCount() != 1 {
+		t.Fatalf("Expected 1 cloze exercise but got %d", qa.GetCount())
+	}
+	if qa.GetQuestion(0) != "Le ____ dort. (The cat sleeps.)" {
+		t.Errorf("Unexpected question %q", qa.GetQuestion(0))
+	}
+	if qa.GetAnswer(0) != "chat" {
+		t.Errorf("Expected answer chat but got %q", qa.GetAnswer(0))
+	}
+}
@@ -0,0 +1,97 @@
+package datamodel
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultPersons are the persons of the conjugation tables of a lesson
+// that does not declare its own.
+var DefaultPersons = []string{"1sg", "2sg", "3sg", "1pl", "2pl", "3pl"}
+
+// notTagCharacters matches what cannot be part of a tag in the name of a
+// tense.
+var notTagCharacters = regexp.MustCompile(`[^\pL\pN_-]+`)
+
+// ConjugationTable is the conjugation of a verb at a tense: one form per
+// person. An empty form is a person the verb has no form for.
+type ConjugationTable struct {
+	Verb    string   `json:"verb"`
+	Tense   string   `json:"tense"`
+	Persons []string `json:"persons"`
+	Forms   []string `json:"forms"`
+}
+
+// NewConjugationTable creates the table of a verb at a tense. An error is
+// returned if there is not one form per person.
+func NewConjugationTable(verb, tense string, persons, forms []string) (ConjugationTable, error) {
+	if len(forms) != len(persons) {
+		return ConjugationTable{}, fmt.Errorf("%s at %s has %d forms for %d persons (%s)", verb, tense, len(forms), len(persons), strings.Join(persons, ", "))
+	}
+	return ConjugationTable{Verb: verb, Tense: tense, Persons: persons, Forms: forms}, nil
+}
+
+// GetTenseTag returns the tag of the questions on the tense of the table,
+// so a drill can be restricted to a tense with --tags.
+func (c ConjugationTable) GetTenseTag() string {
+	return strings.Trim(notTagCharacters.ReplaceAllString(strings.ToLower(c.Tense), "-"), "-")
+}
+
+// BuildQuestionsSet creates a question for each cell of the table, such as
+// "aller, futur, nous?" whose answer is "irons". The form preceded by the
+// person is accepted too.
+func (c ConjugationTable) BuildQuestionsSet() QuestionsAnswers {
+	qa := NewQA()
+	for i, form := range c.Forms {
+		if form == "" {
+			continue
+		}
+		qa.AddEntry(fmt.Sprintf("%s, %s, %s?", c.Verb, c.Tense, c.Persons[i]), form)
+		last := qa.GetCount() - 1
+		qa.SetVariants(last, []string{c.Persons[i] + " " + form})
+		qa.SetTags(last, []string{c.GetTenseTag()})
+	}
+	return qa
+}
+
+// GetConjugations returns the conjugation tables of a lesson. It returns
+// nil if the lesson has none.
+func (topic Topic) GetConjugations(ID string) []ConjugationTable {
+	return topic.conjugations[ID]
+}
+
+// AddConjugation adds a conjugation table to a lesson.
+func (topic *Topic) AddConjugation(ID string, c ConjugationTable) {
+	ID = strings.Trim(ID, " ")
+	topic.conjugations[ID] = append(topic.conjugations[ID], c)
+}
+
+// GetConjugationSubsectionsName returns the sorted list of the lessons that
+// have conjugation tables.
+func (topic Topic) GetConjugationSubsectionsName() []string {
+	subsections := make([]string, 0, len(topic.conjugations))
+	for ID := range topic.conjugations {
+		subsections = append(subsections, ID)
+	}
+	sort.Strings(subsections)
+	return subsections
+}
+
+// BuildConjugationQuestionsSet creates a set of questions from the cells
+// of the conjugation tables of the lessons passed in parameter. If no
+// lesson is supplied, all the tables are used.
+func (topic Topic) BuildConjugationQuestionsSet(ids ...string) QuestionsAnswers {
+	qa := NewQA()
+	var subsections = ids
+	if len(subsections) == 0 {
+		subsections = topic.GetConjugationSubsectionsName()
+	}
+	for _, ID := range subsections {
+		for _, c := range topic.GetConjugations(ID) {
+			qa.Concatenate(c.BuildQuestionsSet())
+		}
+	}
+	return qa
+}
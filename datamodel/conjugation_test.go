@@ -0,0 +1,40 @@
+package datamodel
+
+import "testing"
+
+// TestBuildConjugationQuestionsSet checks that a question is built for
+// each cell of the tables and that the missing forms are left out.
+func TestBuildConjugationQuestionsSet(t *testing.T) {
+	persons := []string{"je", "tu", "il", "nous", "vous", "ils"}
+	futur, err := NewConjugationTable("aller", "futur", persons, []string{"irai", "iras", "ira", "irons", "irez", "iront"})
+	if err != nil {
+		t.Fatalf("the table should be valid. Got %v", err)
+	}
+	pleuvoir, err := NewConjugationTable("pleuvoir", "passé composé", persons, []string{"", "", "a plu", "", "", ""})
+	if err != nil {
+		t.Fatalf("the table should be valid. Got %v", err)
+	}
+	if _, err := NewConjugationTable("aller", "futur", persons, []string{"irai"}); err == nil {
+		t.Errorf("A table without one form per person must be rejected")
+	}
+	topic := NewTopic()
+	topic.AddConjugation("1", futur)
+	topic.AddConjugation("1", pleuvoir)
+
+	qa := topic.BuildConjugationQuestionsSet("1")
+	if qa.GetCount() != 7 {
+		t.Fatalf("Expected 7 cells but got %d", qa.GetCount())
+	}
+	if qa.GetQuestion(3) != "aller, futur, nous?" || qa.GetAnswer(3) != "irons" {
+		t.Errorf("Expected aller, futur, nous? -> irons but got %q -> %q", qa.GetQuestion(3), qa.GetAnswer(3))
+	}
+	if v := qa.GetVariants(3); len(v) != 1 || v[0] != "nous irons" {
+		t.Errorf("The form preceded by the person must be accepted. Got %v", v)
+	}
+	if tags := qa.GetTags(6); len(tags) != 1 || tags[0] != "passé-composé" {
+		t.Errorf("The cells must be tagged with their tense. Got %v", tags)
+	}
+	if qa.Filter(TagFilter{Required: []string{"futur"}}).GetCount() != 6 {
+		t.Errorf("The drill must be restricted to a tense by its tag")
+	}
+}
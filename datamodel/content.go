@@ -0,0 +1,175 @@
+package datamodel
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ToTopic converts the JSON format of the lessons to the content model
+// used by the application. The lessons are identified by their number,
+// formatted with FormatLessonID. The tags and the difficulty of a lesson
+// apply to its resources as in the text format. An error is returned if a
+// lesson is declared twice or if a resource, a rule or a table is invalid.
+func (l Language) ToTopic() (Topic, error) {
+	topic := NewTopic()
+	topic.LearnedLanguage = l.Meta.Learning
+	topic.NativeLanguage = l.Meta.Native
+	topic.Book = l.Meta.Book
+	seen := make(map[int]bool)
+	for _, lesson := range l.Content.Lessons {
+		if seen[lesson.ID] {
+			return NewTopic(), fmt.Errorf("the lesson %d is declared twice", lesson.ID)
+		}
+		seen[lesson.ID] = true
+		ID := FormatLessonID(lesson.ID)
+		if lesson.Title.Learning != "" || lesson.Title.Native != "" {
+			topic.SetLessonTitle(ID, lesson.Title)
+		}
+		if len(lesson.Tags) > 0 || lesson.Difficulty != 0 {
+			topic.SetLessonTags(ID, lesson.Tags, lesson.Difficulty)
+		}
+		vocabulary, err := buildFromResources(lesson, lesson.Vocabulary, Vocabulary)
+		if err != nil {
+			return NewTopic(), errors.Wrapf(err, "vocabulary of the lesson %d", lesson.ID)
+		}
+		if vocabulary.GetCount() > 0 {
+			topic.SetVocabularySubsection(ID, vocabulary)
+			topic.vocabularyCount += vocabulary.GetCount()
+		}
+		sentences, err := buildFromResources(lesson, lesson.Sentences, Sentence)
+		if err != nil {
+			return NewTopic(), errors.Wrapf(err, "sentences of the lesson %d", lesson.ID)
+		}
+		if sentences.GetCount() > 0 {
+			topic.SetSentencesSubsection(ID, sentences)
+			topic.sentencesCount += sentences.GetCount()
+		}
+		if len(lesson.Grammar) > 0 {
+			topic.SetGrammar(ID, lesson.Grammar)
+		}
+		for _, c := range lesson.Conjugations {
+			table, err := NewConjugationTable(c.Verb, c.Tense, c.Persons, c.Forms)
+			if err != nil {
+				return NewTopic(), errors.Wrapf(err, "conjugations of the lesson %d", lesson.ID)
+			}
+			topic.AddConjugation(ID, table)
+		}
+	}
+	return topic, nil
+}
+
+// buildFromResources creates the entries of the resources of a lesson.
+// The question is the native side of the resource and the answer the side
+// in the language to learn.
+func buildFromResources(lesson Lesson, resources []Resource, kind EntryKind) (QuestionsAnswers, error) {
+	qa := NewQA()
+	for _, r := range resources {
+		tags := append(append([]string{}, lesson.Tags...), r.Tags...)
+		if err := CheckTags(NormalizeTags(tags)); err != nil {
+			return qa, err
+		}
+		difficulty := r.Difficulty
+		if difficulty == 0 {
+			difficulty = lesson.Difficulty
+		}
+		if difficulty != 0 {
+			if err := CheckDifficulty(difficulty); err != nil {
+				return qa, err
+			}
+		}
+		qa.AddEntryOfKind(r.Native, StripClozeMarkup(r.Learning), kind)
+		last := qa.GetCount() - 1
+		if HasClozeMarkup(r.Learning) {
+			qa.SetCloze(last, r.Learning)
+		}
+		if len(r.Variants) > 0 {
+			qa.SetVariants(last, r.Variants)
+		}
+		qa.SetID(last, r.ID)
+		qa.SetMedia(last, r.Media)
+		qa.SetWordInfo(last, r.Word)
+		qa.SetTags(last, tags)
+		qa.SetDifficulty(last, difficulty)
+	}
+	return qa, nil
+}
+
+// ToLanguage converts the content model to the JSON format of the lessons.
+// The tags and the difficulty set on a lesson are written on the lesson,
+// the resources only keep their own. An error is returned if the ID of a
+// lesson is not a number.
+func (topic Topic) ToLanguage() (Language, error) {
+	l := Language{
+		Meta: Metadata{
+			Learning: topic.LearnedLanguage,
+			Native:   topic.NativeLanguage,
+			Book:     topic.Book,
+		},
+		Content: NewLanguageResources(),
+	}
+	for _, ID := range topic.GetAllSubsectionsName() {
+		number, err := strconv.Atoi(ID)
+		if err != nil {
+			return Language{}, fmt.Errorf("the lesson %q cannot be written in the json format: its ID is not a number", ID)
+		}
+		lesson := NewLesson(number)
+		lesson.Title = topic.GetLessonTitle(ID)
+		lesson.Tags = topic.GetLessonTags(ID)
+		lesson.Difficulty = topic.GetLessonDifficulty(ID)
+		lesson.Vocabulary = toResources(topic.GetVocabularySubsection(ID), lesson)
+		if sentences, ok := topic.sentences[ID]; ok {
+			lesson.Sentences = toResources(sentences, lesson)
+		}
+		if rules := topic.GetGrammar(ID); rules != nil {
+			lesson.Grammar = rules
+		}
+		lesson.Conjugations = topic.GetConjugations(ID)
+		l.Content.Lessons = append(l.Content.Lessons, lesson)
+	}
+	return l, nil
+}
+
+// toResources creates the resources of the entries of a lesson. The tags
+// and the difficulty inherited from the lesson are left out.
+func toResources(qa QuestionsAnswers, lesson Lesson) []Resource {
+	resources := []Resource{}
+	for i := 0; i < qa.GetCount(); i++ {
+		learning := qa.GetAnswer(i)
+		if marked := qa.GetCloze(i); marked != "" {
+			learning = marked
+		}
+		var tags []string
+		for _, tag := range qa.GetTags(i) {
+			if !containsTag(lesson.Tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		difficulty := qa.GetDifficulty(i)
+		if difficulty == lesson.Difficulty {
+			difficulty = 0
+		}
+		resources = append(resources, Resource{
+			ID:         qa.GetID(i),
+			Learning:   learning,
+			Native:     qa.GetQuestion(i),
+			Variants:   qa.GetVariants(i),
+			Media:      qa.GetMedia(i),
+			Word:       qa.GetWordInfo(i),
+			Tags:       tags,
+			Difficulty: difficulty,
+		})
+	}
+	return resources
+}
+
+// containsTag tells if the tag is one of the tags passed in parameter.
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package datamodel
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const sampleLanguage = `{
+  "metadata": {
+    "learning": "German",
+    "native": "English",
+    "book": {"title": "German with ease", "authors": [{"firstname": "Hilde", "lastname": "Schneider"}], "isbn": "978-2-7005-0000-0"}
+  },
+  "content": {
+    "lessons": [
+      {
+        "id": 1,
+        "title": {"learn": "Im Restaurant", "native": "At the restaurant"},
+        "tags": ["food"],
+        "vocabulary": [
+          {"id": "house", "learn": "Haus", "native": "house", "word": {"pos": "noun", "gender": "n"}},
+          {"learn": "essen", "native": "to eat", "tags": ["verb"], "difficulty": 2}
+        ],
+        "sentences": [
+          {"learn": "Ich {{c1::esse}} gern.", "native": "I like eating.", "variants": ["Gern esse ich."]}
+        ]
+      },
+      {
+        "id": 2,
+        "vocabulary": [],
+        "sentences": [],
+        "conjugations": [{"verb": "sein", "tense": "Präsens", "persons": ["ich", "du"], "forms": ["bin", "bist"]}]
+      }
+    ]
+  }
+}`
+
+// TestLanguageToTopic checks that the JSON format of the lessons is
+// converted to a topic with its book, its titles and its identifiers.
+func TestLanguageToTopic(t *testing.T) {
+	l, err := LoadLessons(strings.NewReader(sampleLanguage))
+	if err != nil {
+		t.Fatalf("Loading of the sample should not fail: %v", err)
+	}
+	topic, err := l.ToTopic()
+	if err != nil {
+		t.Fatalf("Conversion of the sample should not fail: %v", err)
+	}
+	if topic.LearnedLanguage != "German" || topic.Book.String() != "German with ease by Hilde Schneider (ISBN 978-2-7005-0000-0)" {
+		t.Errorf("Unexpected metadata: %q %q", topic.LearnedLanguage, topic.Book)
+	}
+	if names := topic.GetAllSubsectionsName(); len(names) != 2 || names[0] != "01" || names[1] != "02" {
+		t.Fatalf("Expected the lessons 01 and 02 but got %v", names)
+	}
+	if title := topic.GetLessonTitle("01"); title.Learning != "Im Restaurant" {
+		t.Errorf("Unexpected title %+v", title)
+	}
+	vocabulary := topic.GetVocabularySubsection("01")
+	if vocabulary.GetCount() != 2 || vocabulary.GetKey(0) != "house" || vocabulary.GetWordInfo(0).Gender != Neuter {
+		t.Errorf("Unexpected vocabulary: %d entries, key %q", vocabulary.GetCount(), vocabulary.GetKey(0))
+	}
+	if tags := vocabulary.GetTags(1); len(tags) != 2 || vocabulary.GetDifficulty(1) != 2 {
+		t.Errorf("The entry must have the tags of the lesson and its own. Got %v", tags)
+	}
+	sentences := topic.GetSentencesSubsection("01")
+	if sentences.GetAnswer(0) != "Ich esse gern." || sentences.GetCloze(0) == "" || len(sentences.GetVariants(0)) != 1 {
+		t.Errorf("Unexpected sentence %q", sentences.GetAnswer(0))
+	}
+	if topic.GetNumberOfWords() != 2 || topic.GetNumberOfSentences() != 1 {
+		t.Errorf("Expected 2 words and 1 sentence but got %d and %d", topic.GetNumberOfWords(), topic.GetNumberOfSentences())
+	}
+	if len(topic.GetConjugations("02")) != 1 {
+		t.Errorf("The conjugation table of the lesson 02 is missing")
+	}
+
+	l.Content.Lessons = append(l.Content.Lessons, NewLesson(1))
+	if _, err := l.ToTopic(); err == nil {
+		t.Errorf("A lesson declared twice must be reported")
+	}
+}
+
+// TestTopicToLanguage checks that a topic written in the JSON format is
+// read back unchanged.
+func TestTopicToLanguage(t *testing.T) {
+	l, _ := LoadLessons(strings.NewReader(sampleLanguage))
+	topic, _ := l.ToTopic()
+	converted, err := topic.ToLanguage()
+	if err != nil {
+		t.Fatalf("Conversion of the topic should not fail: %v", err)
+	}
+	lesson := converted.Content.Lessons[0]
+	if len(lesson.Tags) != 1 || lesson.Tags[0] != "food" || len(lesson.Vocabulary[0].Tags) != 0 {
+		t.Errorf("The tags of the lesson must be written on the lesson only. Got %v and %v", lesson.Tags, lesson.Vocabulary[0].Tags)
+	}
+	if tags := lesson.Vocabulary[1].Tags; len(tags) != 1 || tags[0] != "verb" || lesson.Vocabulary[1].Difficulty != 2 {
+		t.Errorf("The resources must keep their own tags and difficulty. Got %v and %d", tags, lesson.Vocabulary[1].Difficulty)
+	}
+	var buf bytes.Buffer
+	if err := SaveLessons(&buf, converted); err != nil {
+		t.Fatalf("Saving of the lessons should not fail: %v", err)
+	}
+	reloaded, err := LoadLessons(&buf)
+	if err != nil {
+		t.Fatalf("Loading of the saved lessons should not fail: %v", err)
+	}
+	back, err := reloaded.ToTopic()
+	if err != nil {
+		t.Fatalf("Conversion of the saved lessons should not fail: %v", err)
+	}
+	if back.Book.Title != topic.Book.Title || back.GetLessonTitle("01").Learning != topic.GetLessonTitle("01").Learning {
+		t.Errorf("The book and the titles must be kept")
+	}
+	sentences := back.GetSentencesSubsection("01")
+	if sentences.GetCloze(0) != "Ich {{c1::esse}} gern." || sentences.GetVariants(0)[0] != "Gern esse ich." {
+		t.Errorf("The cloze and the variants must be kept. Got %q %v", sentences.GetCloze(0), sentences.GetVariants(0))
+	}
+	if back.GetVocabularySubsection("01").GetKey(0) != "house" || len(back.GetConjugations("02")) != 1 {
+		t.Errorf("The identifiers and the conjugations must be kept")
+	}
+
+	topic.SetLessonTitle("intro", Resource{Native: "Introduction"})
+	if _, err := topic.ToLanguage(); err == nil {
+		t.Errorf("A lesson whose ID is not a number must be reported")
+	}
+}
@@ -65,3 +65,28 @@ func TestNewTopic(t *testing.T) {
 		t.Errorf("Was expecting 0 but received a count of %d\n", count)
 	}
 }
+
+// TestPickDirection checks that the mixed mode honours the configured
+// ratio of recognition prompts.
+func TestPickDirection(t *testing.T) {
+	p := NewInterrogationParameters()
+	if d := p.PickDirection(); d != Production {
+		t.Errorf("Default direction should be production but got %s", d)
+	}
+	p.SetReverseMode()
+	if d := p.PickDirection(); d != Recognition {
+		t.Errorf("Reversed mode should ask in the recognition direction but got %s", d)
+	}
+	p.SetMixedDirectionMode(0)
+	for i := 0; i < 20; i++ {
+		if d := p.PickDirection(); d != Production {
+			t.Fatalf("With a ratio of 0, all prompts must be in production direction but got %s", d)
+		}
+	}
+	p.SetMixedDirectionMode(1)
+	for i := 0; i < 20; i++ {
+		if d := p.PickDirection(); d != Recognition {
+			t.Fatalf("With a ratio of 1, all prompts must be in recognition direction but got %s", d)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package datamodel
+
+import (
+	"sort"
+	"strings"
+)
+
+// Kinds of the exercises of a grammar rule.
+const (
+	// FillInExercise is a sentence with a blank to fill in
+	FillInExercise = "fill-in"
+	// TransformationExercise is a sentence to transform, such as a
+	// statement to put in the past tense
+	TransformationExercise = "transformation"
+)
+
+// GrammarRule is a rule of grammar explained in a lesson, illustrated by
+// examples and drilled with exercises.
+type GrammarRule struct {
+	// Title names the rule
+	Title string `json:"title"`
+	// Explanation describes the rule. It can span several lines.
+	Explanation string `json:"explanation"`
+	// Examples are sentences applying the rule with their translation
+	Examples []Resource `json:"examples,omitempty"`
+	// Exercises drill the rule
+	Exercises []GrammarExercise `json:"exercises,omitempty"`
+}
+
+// GrammarExercise is an exercise on a grammar rule: the user answers the
+// prompt and her/his answer is compared to the expected one.
+type GrammarExercise struct {
+	// Kind is FillInExercise or TransformationExercise
+	Kind string `json:"kind"`
+	// Prompt is the sentence to complete or to transform
+	Prompt string `json:"prompt"`
+	// Answer is the expected answer
+	Answer string `json:"answer"`
+}
+
+// GetGrammar returns the grammar rules of a lesson. It returns nil if the
+// lesson has none.
+func (topic Topic) GetGrammar(ID string) []GrammarRule {
+	return topic.grammar[ID]
+}
+
+// SetGrammar defines (or overrides if they already existed) the grammar
+// rules of a lesson.
+func (topic *Topic) SetGrammar(ID string, rules []GrammarRule) {
+	topic.grammar[strings.Trim(ID, " ")] = rules
+}
+
+// GetGrammarSubsectionsName returns the sorted list of the lessons that
+// have grammar rules.
+func (topic Topic) GetGrammarSubsectionsName() []string {
+	subsections := make([]string, 0, len(topic.grammar))
+	for ID := range topic.grammar {
+		subsections = append(subsections, ID)
+	}
+	sort.Strings(subsections)
+	return subsections
+}
+
+// BuildGrammarQuestionsSet creates a set of questions from the exercises
+// of the grammar rules of the lessons passed in parameter. If no lesson is
+// supplied, all the grammar rules are used.
+func (topic Topic) BuildGrammarQuestionsSet(ids ...string) QuestionsAnswers {
+	qa := NewQA()
+	var subsections = ids
+	if len(subsections) == 0 {
+		subsections = topic.GetGrammarSubsectionsName()
+	}
+	for _, ID := range subsections {
+		for _, rule := range topic.GetGrammar(ID) {
+			for _, e := range rule.Exercises {
+				qa.AddEntryOfKind(e.Prompt, e.Answer, Sentence)
+			}
+		}
+	}
+	return qa
+}
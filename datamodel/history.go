@@ -0,0 +1,204 @@
+package datamodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/pkg/errors"
+)
+
+const (
+	// HistoryFileName is the name of the file, in the data directory, that
+	// stores the results of the previous sessions.
+	HistoryFileName = "history.json"
+
+	// SessionsLogFileName is the name of the file, in the data directory,
+	// where each session is logged with the parameters needed to replay it.
+	SessionsLogFileName = "sessions.log"
+
+	// CommandsHistoryFileName is the name of the file, in the data
+	// directory, where the commands typed in the interpreter are kept.
+	CommandsHistoryFileName = "commands.history"
+
+	// ErrorScoreWeight is the weight given to the error score of an item
+	// when computing its weight for the weighted mode.
+	ErrorScoreWeight = 3.0
+
+	// ErrorScoreDecay is the factor applied to the error score each time
+	// the item is answered correctly. This makes an item that was missed
+	// long ago come back less and less often.
+	ErrorScoreDecay = 0.5
+
+	// SlowResponseThreshold is the response time above which an answer is
+	// considered as slow. A slow answer makes the item come back more often.
+	SlowResponseThreshold = 5 * time.Second
+
+	// MaxSlownessBonus caps the weight that the response time can add to an
+	// item so a single very long pause does not take over the session.
+	MaxSlownessBonus = 2.0
+
+	// responseTimeSmoothing is the factor used to compute the moving
+	// average of the response times.
+	responseTimeSmoothing = 0.3
+)
+
+// ItemRecord stores the results of an item across the sessions.
+type ItemRecord struct {
+	// Asked is the number of times the item was graded
+	Asked int `json:"asked"`
+	// Misses is the number of wrong answers given for this item
+	Misses int `json:"misses"`
+	// ErrorScore is increased on each miss and decays on each correct answer
+	ErrorScore float64 `json:"errorScore"`
+	// AverageResponse is the moving average of the response times
+	AverageResponse time.Duration `json:"averageResponse"`
+	// LastAsked is the last time the item was graded
+	LastAsked time.Time `json:"lastAsked"`
+	// Flagged tells that the user has marked the item to review it later
+	Flagged bool `json:"flagged,omitempty"`
+}
+
+// History stores the results recorded for the items, indexed by the key
+// of the item (see QuestionsAnswers.GetKey).
+type History struct {
+	Items map[string]*ItemRecord `json:"items"`
+}
+
+// NewHistory creates an empty history.
+func NewHistory() History {
+	return History{
+		Items: make(map[string]*ItemRecord),
+	}
+}
+
+// LoadHistory reads the history stored in the file passed in parameter. If
+// the file does not exist, an empty history is returned.
+func LoadHistory(path string) (History, error) {
+	exists, err := tools.FileExists(path)
+	if err != nil {
+		return NewHistory(), errors.Wrapf(err, "failed to check if history file %q exists", path)
+	}
+	if !exists {
+		return NewHistory(), nil
+	}
+	ba, err := ioutil.ReadFile(path)
+	if err != nil {
+		return NewHistory(), errors.Wrapf(err, "failed to read history file %q", path)
+	}
+	h := NewHistory()
+	err = json.Unmarshal(ba, &h)
+	if err != nil {
+		return NewHistory(), errors.Wrapf(err, "failed to decode history file %q", path)
+	}
+	if h.Items == nil {
+		h.Items = make(map[string]*ItemRecord)
+	}
+	return h, nil
+}
+
+// Save writes the history to the file passed in parameter. The file is
+// overwritten if it already exists.
+func (h History) Save(path string) error {
+	ba, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the history")
+	}
+	return tools.SaveBytesToFile(ba, path, true)
+}
+
+// historyUpdates serializes the updates of the history files by the
+// sessions of the process.
+var historyUpdates sync.Mutex
+
+// UpdateHistory reads the history stored in the file passed in parameter,
+// applies the changes of a session to it and saves it. Reading the file
+// again keeps the results saved by the other sessions since this one
+// started. The updated history is returned.
+func UpdateHistory(path string, update func(h *History)) (History, error) {
+	historyUpdates.Lock()
+	defer historyUpdates.Unlock()
+	h, err := LoadHistory(path)
+	if err != nil {
+		return h, err
+	}
+	update(&h)
+	return h, h.Save(path)
+}
+
+// Record stores the result of an answer for the item identified by key.
+// A correct answer makes the error score decay while a miss increases it.
+func (h *History) Record(key string, correct bool, elapsed time.Duration) {
+	r, ok := h.Items[key]
+	if !ok {
+		r = &ItemRecord{}
+		h.Items[key] = r
+	}
+	if r.Asked == 0 {
+		r.AverageResponse = elapsed
+	} else {
+		r.AverageResponse = time.Duration(responseTimeSmoothing*float64(elapsed) + (1-responseTimeSmoothing)*float64(r.AverageResponse))
+	}
+	r.Asked++
+	r.LastAsked = time.Now()
+	if correct {
+		r.ErrorScore *= ErrorScoreDecay
+		return
+	}
+	r.Misses++
+	r.ErrorScore++
+}
+
+// ToggleFlag marks the item identified by key to review it later, or
+// removes the mark if it is already set. It returns the new state.
+func (h *History) ToggleFlag(key string) bool {
+	r, ok := h.Items[key]
+	if !ok {
+		r = &ItemRecord{}
+		h.Items[key] = r
+	}
+	r.Flagged = !r.Flagged
+	return r.Flagged
+}
+
+// IsFlagged tells if the item identified by key is marked to be reviewed.
+func (h History) IsFlagged(key string) bool {
+	r, ok := h.Items[key]
+	return ok && r.Flagged
+}
+
+// Weight returns the weight of an item for the weighted mode. An item that
+// was never recorded has a weight of 1. Misses and slow answers increase
+// the weight.
+func (h History) Weight(key string) float64 {
+	r, ok := h.Items[key]
+	if !ok {
+		return 1
+	}
+	return 1 + ErrorScoreWeight*r.ErrorScore + slownessBonus(r.AverageResponse)
+}
+
+// Explain returns a human readable explanation of the weight of an item.
+func (h History) Explain(key string) string {
+	r, ok := h.Items[key]
+	if !ok {
+		return fmt.Sprintf("weight %.2f: never recorded", h.Weight(key))
+	}
+	return fmt.Sprintf("weight %.2f: %d misses out of %d, error score %.2f, average response %s (+%.2f)",
+		h.Weight(key), r.Misses, r.Asked, r.ErrorScore, r.AverageResponse.Round(100*time.Millisecond), slownessBonus(r.AverageResponse))
+}
+
+// slownessBonus computes the part of the weight that is due to slow answers.
+func slownessBonus(averageResponse time.Duration) float64 {
+	if averageResponse <= SlowResponseThreshold {
+		return 0
+	}
+	bonus := float64(averageResponse)/float64(SlowResponseThreshold) - 1
+	if bonus > MaxSlownessBonus {
+		bonus = MaxSlownessBonus
+	}
+	return bonus
+}
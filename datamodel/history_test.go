@@ -0,0 +1,51 @@
+package datamodel
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestHistoryWeight checks that a miss increases the weight of an item and
+// that correct answers make it decay.
+func TestHistoryWeight(t *testing.T) {
+	h := NewHistory()
+	if w := h.Weight("unknown"); w != 1 {
+		t.Errorf("An item never recorded should have a weight of 1 but has %f", w)
+	}
+	h.Record("item", false, time.Second)
+	missed := h.Weight("item")
+	if missed <= 1 {
+		t.Errorf("A missed item should have a weight greater than 1 but has %f", missed)
+	}
+	h.Record("item", true, time.Second)
+	decayed := h.Weight("item")
+	if decayed >= missed {
+		t.Errorf("A correct answer should decrease the weight. Was %f, is now %f", missed, decayed)
+	}
+	h.Record("slow", true, 3*SlowResponseThreshold)
+	if w := h.Weight("slow"); w <= 1 {
+		t.Errorf("A slow answer should increase the weight but it is %f", w)
+	}
+}
+
+// TestHistorySaveAndLoad checks that the history survives a round trip to
+// the disk.
+func TestHistorySaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HistoryFileName)
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("loading a missing history must not fail. Received: %v", err)
+	}
+	h.Record("item", false, time.Second)
+	if err = h.Save(path); err != nil {
+		t.Fatalf("saving the history must not fail. Received: %v", err)
+	}
+	loaded, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("loading the history must not fail. Received: %v", err)
+	}
+	if loaded.Weight("item") != h.Weight("item") {
+		t.Errorf("Expected weight %f after reload but got %f", h.Weight("item"), loaded.Weight("item"))
+	}
+}
@@ -2,7 +2,9 @@ package datamodel
 
 import (
 	"io"
+	"math/rand"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -22,6 +24,10 @@ const (
 	// Summary requires to show the list of subsections so the user has
 	// a clear view of what the available content is.
 	Summary
+	// Weighted configures the engine to ask more often the questions that
+	// were missed recently or answered slowly. It relies on the history of
+	// the previous sessions.
+	Weighted
 
 	// DefaultInterrogationPause is the default pause time between 2
 	// questions. Default value is 2 seconds.
@@ -29,6 +35,10 @@ const (
 
 	// DefaultLoopCount is the default number of loops during an interrogation.
 	DefaultLoopCount = 10
+
+	// DefaultRecognitionRatio is the default share of prompts asked in the
+	// recognition direction when the directions are mixed.
+	DefaultRecognitionRatio = 0.5
 )
 
 // InterrogationParameters is a datastructure that contains the parameters required
@@ -49,16 +59,68 @@ type InterrogationParameters struct {
 	limit int
 	// Requires that questions becomes answers and answers becomes questions
 	reversed bool
+	// Requires that each prompt picks its direction randomly
+	mixed bool
+	// Share of the prompts asked in the recognition direction in mixed mode
+	recognitionRatio float64
 	// Experimental. Channel to receive questions and answers
 	Qachan chan string
-	// Experimental. Channel to receive commands
+	// Channel to receive the commands sent on the control socket: next,
+	// reveal, grade <1-4> or quit
 	Command chan string
+	// Channel to acknowledge each command received on Command
+	Replies chan string
 	// Experimental. Channel to publish to the output. This channel collects all that needs to be displayed to the user.
 	Publisher chan string
 	// Absolute path to the lesson file to use
 	lessonsFile string
 	// tells if we accept to have multiple times the same word asked in a loop or not
 	AvoidRepetition bool
+	// Directory where the results of the sessions are stored. If empty,
+	// nothing is persisted.
+	dataDir string
+	// Requires to display the weights of the items in weighted mode
+	explain bool
+	// seed used to initialize the random source. Knowing it makes it
+	// possible to replay a session in the exact same order.
+	seed int64
+	// the random source used to draw the questions
+	rng *rand.Rand
+	// Requires that the pause between the question and the answer depends
+	// on the length of the question
+	adaptive bool
+	// Timing policies used when the pause is adaptive
+	vocabularyTiming TimingPolicy
+	sentencesTiming  TimingPolicy
+	// How the questions and the answers are spoken
+	speech SpeechSettings
+	// How the audio clips attached to the entries are played
+	player PlayerSettings
+	// Requires to hide the text of the questions so they are only heard
+	listeningOnly bool
+	// Languages of the question column and of the answer column
+	nativeLanguage  string
+	learnedLanguage string
+	// Maximum duration of the session. Zero means no limit.
+	maxDuration time.Duration
+	// Maximum number of questions asked during the session. Zero means no
+	// limit.
+	maxQuestions int
+	// Kind of questions set the session is built from (vocabulary,
+	// sentences...). It is needed to rebuild the set when resuming.
+	exercise string
+	// Selects the entries of the questions set on their tags
+	tags TagFilter
+	// Selects the entries of the questions set on their difficulty
+	difficulty DifficultyRange
+	// State of the interrupted session to go on with
+	resumed *Snapshot
+	// Path of the Unix socket receiving the commands that drive the
+	// session. Empty means no socket.
+	controlSocket string
+	// Address of the HTTP server streaming the events of the session.
+	// Empty means the events are not streamed.
+	eventsAddress string
 }
 
 // NewInterrogationParameters creates a default instance of the
@@ -70,25 +132,37 @@ type InterrogationParameters struct {
 //   * the output is io.Stdout
 //   * the number of loops is 10
 //   * the interrogation is not in Jeopardy mode
+//   * the random source is seeded with the current time
+//   * the pause is adaptive if pause.adaptive is set in the configuration
 func NewInterrogationParameters() InterrogationParameters {
 	loopCount := DefaultLoopCount
 	configuredLoopCount := viper.GetInt("limit")
 	if configuredLoopCount != 0 {
 		loopCount = configuredLoopCount
 	}
+	seed := time.Now().UTC().UnixNano()
 	return InterrogationParameters{
-		interactive:     false,
-		wait:            DefaultInterrogationPause,
-		mode:            Random,
-		in:              os.Stdin,
-		out:             os.Stdout,
-		limit:           loopCount,
-		reversed:        false,
-		lessonsFile:     "NoFileDefined",
-		Qachan:          make(chan string),
-		Publisher:       make(chan string),
-		Command:         make(chan string),
-		AvoidRepetition: true,
+		interactive:      false,
+		wait:             DefaultInterrogationPause,
+		mode:             Random,
+		in:               os.Stdin,
+		out:              os.Stdout,
+		limit:            loopCount,
+		reversed:         false,
+		recognitionRatio: DefaultRecognitionRatio,
+		lessonsFile:      "NoFileDefined",
+		Qachan:           make(chan string),
+		Publisher:        make(chan string),
+		Command:          make(chan string),
+		Replies:          make(chan string),
+		AvoidRepetition:  true,
+		seed:             seed,
+		rng:              rand.New(rand.NewSource(seed)),
+		adaptive:         viper.GetBool("pause.adaptive"),
+		vocabularyTiming: LoadTimingPolicy("pause.vocabulary", DefaultVocabularyTiming),
+		sentencesTiming:  LoadTimingPolicy("pause.sentences", DefaultSentencesTiming),
+		speech:           LoadSpeechSettings(),
+		player:           LoadPlayerSettings(),
 	}
 }
 
@@ -121,6 +195,75 @@ func (p *InterrogationParameters) SetLinearMode() {
 	p.mode = Linear
 }
 
+// IsWeightedMode tells if the questioning favours the items that were
+// missed or answered slowly in the previous sessions.
+func (p *InterrogationParameters) IsWeightedMode() bool {
+	return p.mode == Weighted
+}
+
+// SetWeightedMode requires the interrogation to draw randomly the questions
+// with a probability based on the results recorded for each of them.
+func (p *InterrogationParameters) SetWeightedMode() {
+	p.mode = Weighted
+}
+
+// IsExplainMode tells if the user wants to see why the items are chosen.
+func (p *InterrogationParameters) IsExplainMode() bool {
+	return p.explain
+}
+
+// SetExplainMode requires to display the weight of each item, and the
+// reasons of this weight, before the interrogation starts.
+func (p *InterrogationParameters) SetExplainMode() {
+	p.explain = true
+}
+
+// GetDataDir returns the directory where the results of the sessions are
+// stored. An empty string means that nothing is persisted.
+func (p *InterrogationParameters) GetDataDir() string {
+	return p.dataDir
+}
+
+// SetDataDir changes the directory where the results of the sessions are
+// stored.
+func (p *InterrogationParameters) SetDataDir(dir string) {
+	p.dataDir = dir
+}
+
+// GetControlSocket returns the path of the Unix socket receiving the
+// commands that drive the session. It is empty if the session is only
+// driven from the terminal.
+func (p *InterrogationParameters) GetControlSocket() string {
+	return p.controlSocket
+}
+
+// SetControlSocket requires the session to accept commands on a Unix socket
+// created at this path.
+func (p *InterrogationParameters) SetControlSocket(path string) {
+	p.controlSocket = path
+}
+
+// GetEventsAddress returns the address on which the events of the session
+// are streamed. It is empty if they are not streamed.
+func (p *InterrogationParameters) GetEventsAddress() string {
+	return p.eventsAddress
+}
+
+// SetEventsAddress requires the events of the session to be streamed as
+// server-sent events on this address (localhost:8081 for instance).
+func (p *InterrogationParameters) SetEventsAddress(addr string) {
+	p.eventsAddress = addr
+}
+
+// GetHistoryFile returns the path to the file storing the results of the
+// previous sessions. It returns an empty string if no data directory is set.
+func (p *InterrogationParameters) GetHistoryFile() string {
+	if p.dataDir == "" {
+		return ""
+	}
+	return filepath.Join(p.dataDir, HistoryFileName)
+}
+
 // SetRandomMode requires the interrogation to be done in random mode instead of
 // linear mode.
 func (p *InterrogationParameters) SetRandomMode() {
@@ -158,6 +301,48 @@ func (p *InterrogationParameters) SetReverseMode() {
 	p.reversed = true
 }
 
+// UnsetReverseMode requires the questions to be asked in the same direction
+// as in the file.
+func (p *InterrogationParameters) UnsetReverseMode() {
+	p.reversed = false
+}
+
+// IsMixedDirectionMode tells if each prompt picks its direction randomly.
+func (p *InterrogationParameters) IsMixedDirectionMode() bool {
+	return p.mixed
+}
+
+// SetMixedDirectionMode requires that each prompt picks randomly its
+// direction: normal (production) or reversed (recognition). The ratio is
+// the share of prompts asked in the recognition direction. It must be
+// between 0 and 1.
+func (p *InterrogationParameters) SetMixedDirectionMode(recognitionRatio float64) {
+	p.mixed = true
+	p.recognitionRatio = recognitionRatio
+}
+
+// GetRecognitionRatio returns the share of prompts asked in the recognition
+// direction in mixed mode.
+func (p *InterrogationParameters) GetRecognitionRatio() float64 {
+	return p.recognitionRatio
+}
+
+// PickDirection returns the direction of the next prompt. In mixed mode,
+// the direction is drawn with the random source of the session.
+func (p *InterrogationParameters) PickDirection() Direction {
+	switch {
+	case p.mixed:
+		if p.rng.Float64() < p.recognitionRatio {
+			return Recognition
+		}
+		return Production
+	case p.reversed:
+		return Recognition
+	default:
+		return Production
+	}
+}
+
 // GetListOfSubsections returns a string array containing all the subsections selected by
 // the end user.
 func (p *InterrogationParameters) GetListOfSubsections() []string {
@@ -167,6 +352,131 @@ func (p *InterrogationParameters) GetListOfSubsections() []string {
 	return strings.Split(p.subsections, ",")
 }
 
+// SetListOfSubsections records the subsections selected by the end user.
+// The subsections are separated by a comma.
+func (p *InterrogationParameters) SetListOfSubsections(subsections ...string) {
+	p.subsections = strings.Join(subsections, ",")
+}
+
+// GetSeed returns the seed of the random source used to draw the questions.
+func (p *InterrogationParameters) GetSeed() int64 {
+	return p.seed
+}
+
+// SetSeed resets the random source with the seed passed in parameter. Two
+// sessions started with the same seed on the same selection ask the
+// questions in the same order.
+func (p *InterrogationParameters) SetSeed(seed int64) {
+	p.seed = seed
+	p.rng = rand.New(rand.NewSource(seed))
+}
+
+// GetRandom returns the random source used to draw the questions.
+func (p *InterrogationParameters) GetRandom() *rand.Rand {
+	return p.rng
+}
+
+// GetSessionsLogFile returns the path to the file where the sessions are
+// logged. It returns an empty string if no data directory is set.
+func (p *InterrogationParameters) GetSessionsLogFile() string {
+	if p.dataDir == "" {
+		return ""
+	}
+	return filepath.Join(p.dataDir, SessionsLogFileName)
+}
+
+// GetSnapshotFile returns the path to the file where an interrupted session
+// is saved. It returns an empty string if no data directory is set.
+func (p *InterrogationParameters) GetSnapshotFile() string {
+	if p.dataDir == "" {
+		return ""
+	}
+	return filepath.Join(p.dataDir, SnapshotFileName)
+}
+
+// GetExercise returns the kind of questions set the session is built from.
+func (p *InterrogationParameters) GetExercise() string {
+	return p.exercise
+}
+
+// SetExercise records the kind of questions set the session is built from
+// so an interrupted session can be rebuilt.
+func (p *InterrogationParameters) SetExercise(exercise string) {
+	p.exercise = exercise
+}
+
+// GetTagFilter returns the filter selecting the entries of the questions
+// set on their tags. An empty filter selects all the entries.
+func (p *InterrogationParameters) GetTagFilter() TagFilter {
+	return p.tags
+}
+
+// SetTagFilter records the filter selecting the entries of the questions
+// set on their tags.
+func (p *InterrogationParameters) SetTagFilter(f TagFilter) {
+	p.tags = f
+}
+
+// GetDifficultyRange returns the range selecting the entries of the
+// questions set on their difficulty. An empty range selects all the
+// entries.
+func (p *InterrogationParameters) GetDifficultyRange() DifficultyRange {
+	return p.difficulty
+}
+
+// SetDifficultyRange records the range selecting the entries of the
+// questions set on their difficulty.
+func (p *InterrogationParameters) SetDifficultyRange(r DifficultyRange) {
+	p.difficulty = r
+}
+
+// GetResumedSnapshot returns the state of the interrupted session to go on
+// with or nil if the session starts from scratch.
+func (p *InterrogationParameters) GetResumedSnapshot() *Snapshot {
+	return p.resumed
+}
+
+// ResumeFrom restores the settings of an interrupted session: the selection,
+// the mode, the seed and the limits. The engine then goes on from the
+// progress recorded in the snapshot.
+func (p *InterrogationParameters) ResumeFrom(s *Snapshot) {
+	p.resumed = s
+	p.lessonsFile = s.LessonsFile
+	p.exercise = s.Exercise
+	// the filter was valid when the snapshot was saved
+	p.tags, _ = ParseTagFilter(s.Tags)
+	p.difficulty, _ = ParseDifficultyRange(s.Difficulty)
+	p.SetListOfSubsections(s.Subsections...)
+	p.mode = s.Mode
+	p.interactive = s.Interactive
+	p.reversed = s.Reversed
+	p.mixed = s.Mixed
+	p.recognitionRatio = s.RecognitionRatio
+	p.SetSeed(s.Seed)
+	p.limit = s.Limit
+	p.maxDuration = s.MaxDuration
+	p.maxQuestions = s.MaxQuestions
+}
+
+// GetCommandsHistoryFile returns the path to the file where the commands
+// typed in the interpreter are kept. It returns an empty string if no data
+// directory is set.
+func (p *InterrogationParameters) GetCommandsHistoryFile() string {
+	if p.dataDir == "" {
+		return ""
+	}
+	return filepath.Join(p.dataDir, CommandsHistoryFileName)
+}
+
+// ResetChannels creates new channels for a new session. The channels are
+// closed at the end of a session so they cannot be reused.
+func (p *InterrogationParameters) ResetChannels() {
+	p.Qachan = make(chan string)
+	p.Publisher = make(chan string)
+	p.Command = make(chan string)
+	p.Replies = make(chan string)
+}
+
 // GetLimit returns the number of loops for lessons to learn.
 func (p *InterrogationParameters) GetLimit() int {
 	return p.limit
@@ -177,6 +487,97 @@ func (p *InterrogationParameters) SetLimit(newLimit int) {
 	p.limit = newLimit
 }
 
+// GetSpeechSettings returns how the questions and the answers are spoken.
+func (p *InterrogationParameters) GetSpeechSettings() SpeechSettings {
+	return p.speech
+}
+
+// SetSpeechSettings changes how the questions and the answers are spoken.
+func (p *InterrogationParameters) SetSpeechSettings(s SpeechSettings) {
+	p.speech = s
+}
+
+// IsSpeechEnabled tells if the questions and the answers are spoken.
+func (p *InterrogationParameters) IsSpeechEnabled() bool {
+	return p.speech.Enabled
+}
+
+// EnableSpeech requires that the questions and the answers are spoken
+// with the command configured in the speech settings.
+func (p *InterrogationParameters) EnableSpeech() {
+	p.speech.Enabled = true
+}
+
+// GetPlayerSettings returns how the audio clips are played.
+func (p *InterrogationParameters) GetPlayerSettings() PlayerSettings {
+	return p.player
+}
+
+// SetPlayerSettings changes how the audio clips are played.
+func (p *InterrogationParameters) SetPlayerSettings(s PlayerSettings) {
+	p.player = s
+}
+
+// GetMediaDir returns the folder against which the relative paths of the
+// audio clips are resolved: the folder of the lessons file.
+func (p *InterrogationParameters) GetMediaDir() string {
+	return filepath.Dir(p.lessonsFile)
+}
+
+// IsListeningOnlyMode tells if the text of the questions is hidden.
+func (p *InterrogationParameters) IsListeningOnlyMode() bool {
+	return p.listeningOnly
+}
+
+// SetListeningOnlyMode hides the text of the questions: they are only
+// spoken. This enables the speech too.
+func (p *InterrogationParameters) SetListeningOnlyMode() {
+	p.listeningOnly = true
+	p.speech.Enabled = true
+}
+
+// SetLanguages records the languages of the lessons: the native language is
+// the one of the question column, the learned language is the one of the
+// answer column.
+func (p *InterrogationParameters) SetLanguages(native, learned string) {
+	p.nativeLanguage = native
+	p.learnedLanguage = learned
+}
+
+// GetLanguagesFor returns the language of the question and the language of
+// the answer for a prompt asked in the direction passed in parameter.
+func (p *InterrogationParameters) GetLanguagesFor(d Direction) (string, string) {
+	if d == Recognition {
+		return p.learnedLanguage, p.nativeLanguage
+	}
+	return p.nativeLanguage, p.learnedLanguage
+}
+
+// GetMaxDuration returns the maximum duration of a session. Zero means
+// that the session is only limited by the number of loops.
+func (p *InterrogationParameters) GetMaxDuration() time.Duration {
+	return p.maxDuration
+}
+
+// SetMaxDuration stops the session once the duration is elapsed, even if
+// the number of loops is not reached.
+func (p *InterrogationParameters) SetMaxDuration(d time.Duration) {
+	p.maxDuration = d
+}
+
+// GetMaxQuestions returns the maximum number of questions asked during a
+// session. Zero means that the session is only limited by the number of
+// loops.
+func (p *InterrogationParameters) GetMaxQuestions() int {
+	return p.maxQuestions
+}
+
+// SetMaxQuestions stops the session once the number of questions is
+// reached, even if the number of loops is not reached.
+func (p *InterrogationParameters) SetMaxQuestions(n int) {
+	p.maxQuestions = n
+}
+
 // GetLessonsFile returns the absolute path to the lessons file that is used
 // to ask questions to the user.
 func (p *InterrogationParameters) GetLessonsFile() string {
@@ -195,6 +596,35 @@ func (p *InterrogationParameters) GetPauseTime() time.Duration {
 }
 
 // SetPauseTime returns the pause between each question in milliseconds.
+// Setting a pause overrides the adaptive pause.
 func (p *InterrogationParameters) SetPauseTime(newWaitTime time.Duration) {
 	p.wait = newWaitTime
+	p.adaptive = false
+}
+
+// IsAdaptivePause tells if the pause between the question and the answer
+// depends on the length of the question.
+func (p *InterrogationParameters) IsAdaptivePause() bool {
+	return p.adaptive
+}
+
+// SetAdaptivePause requires that the pause between the question and the
+// answer is computed with the timing policies passed in parameter: one for
+// the vocabulary, the other one for the sentences.
+func (p *InterrogationParameters) SetAdaptivePause(vocabulary, sentences TimingPolicy) {
+	p.adaptive = true
+	p.vocabularyTiming = vocabulary
+	p.sentencesTiming = sentences
+}
+
+// GetPauseFor returns the pause to respect, in unattended mode, before
+// revealing the answer of the question passed in parameter.
+func (p *InterrogationParameters) GetPauseFor(kind EntryKind, question string) time.Duration {
+	if !p.adaptive {
+		return p.wait
+	}
+	if kind == Sentence {
+		return p.sentencesTiming.Delay(question)
+	}
+	return p.vocabularyTiming.Delay(question)
 }
@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
 // Language is the modelization of a structure that stores data to learn
-// a language.
+// a language, as written in the JSON format of the lessons file. It is
+// converted to a Topic, the content model of the application, with ToTopic.
 // The structure was built based on a book so it may not be relevant for
 // other learning material.
 type Language struct {
@@ -41,6 +43,19 @@ func LoadLessons(r io.Reader) (Language, error) {
 	return output, nil
 }
 
+// SaveLessons writes the json structure that represents the resources of
+// the language to learn. It is the reverse of LoadLessons.
+func SaveLessons(w io.Writer, l Language) error {
+	ba, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to transform the language resource to json")
+	}
+	if _, err := w.Write(append(ba, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write the language resource")
+	}
+	return nil
+}
+
 // LanguageResources describes the different elements useful to learn a
 // language (words, sentences, grammar, ...). Those elements are grouped
 // into  lessons datastructure.
@@ -89,10 +104,18 @@ type Lesson struct {
 	// ID is the unique identifier of the lesson
 	ID int `json:"id"`
 	// Title is the title of the lesson so one can check what it is about
-	Title Resource `json:"title"`
-	// Still need to add the vocabulary, the grammary and the sentences
+	Title      Resource   `json:"title"`
 	Vocabulary []Resource `json:"vocabulary"`
 	Sentences  []Resource `json:"sentences"`
+	// Grammar are the rules of grammar taught in the lesson
+	Grammar []GrammarRule `json:"grammar,omitempty"`
+	// Conjugations are the conjugation tables of the verbs of the lesson
+	Conjugations []ConjugationTable `json:"conjugations,omitempty"`
+	// Tags are inherited by all the resources of the lesson
+	Tags []string `json:"tags,omitempty"`
+	// Difficulty applies to the resources of the lesson that do not set
+	// their own. 0 means it is not set.
+	Difficulty int `json:"difficulty,omitempty"`
 }
 
 // NewLesson is the default constructor for a lesson. All fields, except ID,
@@ -103,6 +126,7 @@ func NewLesson(ID int) Lesson {
 		Title:      Resource{},
 		Vocabulary: []Resource{},
 		Sentences:  []Resource{},
+		Grammar:    []GrammarRule{},
 	}
 }
 
@@ -110,10 +134,29 @@ func NewLesson(ID int) Lesson {
 // in the native language (native and language to learn are based on the
 // Metadata structure.
 type Resource struct {
-	// Learning is the resource in the language you want to learn
+	// ID identifies the resource. The results of the resource are kept
+	// under this identifier so they survive a correction of its text. If
+	// it is empty, the resource is identified by its text.
+	ID string `json:"id,omitempty"`
+	// Learning is the resource in the language you want to learn. It may
+	// contain cloze deletions such as {{c1::word}}.
 	Learning string `json:"learn"`
 	// Native is the resource in the language in your language
 	Native string `json:"native"`
+	// Variants are the other acceptable answers in the language you want
+	// to learn
+	Variants []string `json:"variants,omitempty"`
+	// Media is an optional audio clip of the resource in the language you
+	// want to learn
+	Media *Media `json:"media,omitempty"`
+	// Word is the optional grammatical information of a vocabulary
+	// resource: part of speech, gender, plural and irregular forms
+	Word *WordInfo `json:"word,omitempty"`
+	// Tags are the themes of the resource such as verb or food
+	Tags []string `json:"tags,omitempty"`
+	// Difficulty is set by the author between MinDifficulty and
+	// MaxDifficulty. 0 means it is not set.
+	Difficulty int `json:"difficulty,omitempty"`
 }
 
 // Metadata is the data that describes the learning material.
@@ -138,6 +181,23 @@ type Book struct {
 	ISBN string `json:"isbn"`
 }
 
+// String returns the title of the book followed by its authors and its
+// ISBN when they are known.
+func (b Book) String() string {
+	s := b.Title
+	if len(b.Authors) > 0 {
+		names := make([]string, len(b.Authors))
+		for i, a := range b.Authors {
+			names[i] = a.String()
+		}
+		s += " by " + strings.Join(names, ", ")
+	}
+	if b.ISBN != "" {
+		s += " (ISBN " + b.ISBN + ")"
+	}
+	return s
+}
+
 // Author is a datastructure to fill data about a book
 type Author struct {
 	// Firstname of the author
@@ -145,3 +205,8 @@ type Author struct {
 	// Lastname of the author
 	Lastname string `json:"lastname"`
 }
+
+// String returns the full name of the author.
+func (a Author) String() string {
+	return strings.TrimSpace(a.Firstname + " " + a.Lastname)
+}
@@ -10,10 +10,24 @@ const (
 	// from answers in the CSV file
 	DefaultQaSep = ";"
 
+	// VariantsSep is the string that separates the acceptable variants of
+	// an answer in the lessons file: "Le chat dort. || Il dort, le chat."
+	VariantsSep = "||"
+
 	// Sentences is the string that is searched in the vocabulary file to
 	// delimit the sentences. The lesson number of the sentences should be
 	// right after the delimiter, on the same line.
 	SentencesDelimiter = "### Sentences Lesson"
+
+	// GrammarDelimiter is the string that is searched in the vocabulary file
+	// to delimit the grammar rules. The lesson number of the rules should be
+	// right after the delimiter, on the same line.
+	GrammarDelimiter = "### Grammar Lesson"
+
+	// ConjugationDelimiter is the string that is searched in the vocabulary
+	// file to delimit the conjugation tables. The lesson number of the
+	// tables should be right after the delimiter, on the same line.
+	ConjugationDelimiter = "### Conjugation Lesson"
 )
 
 // TopicParsingParameters is a data structure that helps to parse the lines that
@@ -27,6 +41,15 @@ type TopicParsingParameters struct {
 	// section in the csv file.
 	// The text after this string will be considered as the ID of the topic.
 	SentenceAnnounce string
+	// GrammarAnnounce is the string that is used to announce the grammar
+	// section in the csv file. If it is empty, GrammarDelimiter is used.
+	// The text after this string will be considered as the ID of the topic.
+	GrammarAnnounce string
+	// ConjugationAnnounce is the string that is used to announce the
+	// conjugation section in the csv file. If it is empty,
+	// ConjugationDelimiter is used.
+	// The text after this string will be considered as the ID of the topic.
+	ConjugationAnnounce string
 	// QaSep is the separator on the line between the question and the answer in
 	// the csv file. If this separator is found multiple times on the line, the
 	// first one is considered as the separator.
@@ -37,8 +60,10 @@ type TopicParsingParameters struct {
 // to parse the CSV file containing the questions/answers.
 func NewTopicParsingParameters() TopicParsingParameters {
 	return TopicParsingParameters{
-		LessonAnnounce:   LessonDelimiter,
-		SentenceAnnounce: SentencesDelimiter,
-		QaSep:            DefaultQaSep,
+		LessonAnnounce:      LessonDelimiter,
+		SentenceAnnounce:    SentencesDelimiter,
+		GrammarAnnounce:     GrammarDelimiter,
+		ConjugationAnnounce: ConjugationDelimiter,
+		QaSep:               DefaultQaSep,
 	}
 }
@@ -0,0 +1,76 @@
+package datamodel
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// MediaFilePlaceholder is replaced, in the player command template, by
+	// the path to the audio file.
+	MediaFilePlaceholder = "{file}"
+	// MediaStartPlaceholder is replaced, in the player command template, by
+	// the offset, in seconds, where the clip starts.
+	MediaStartPlaceholder = "{start}"
+	// MediaEndPlaceholder is replaced, in the player command template, by
+	// the offset, in seconds, where the clip ends.
+	MediaEndPlaceholder = "{end}"
+)
+
+// Media references an audio clip attached to a word or a sentence. The clip
+// can be a part of a longer file (a whole lesson recorded on a CD for
+// instance) thanks to the offsets.
+type Media struct {
+	// Path is the path to the audio file. A relative path is relative to
+	// the folder of the lessons file.
+	Path string `json:"path"`
+	// Start is the offset, in seconds, where the clip starts. Zero means
+	// the beginning of the file.
+	Start float64 `json:"start,omitempty"`
+	// End is the offset, in seconds, where the clip ends. Zero means the
+	// end of the file.
+	End float64 `json:"end,omitempty"`
+}
+
+// Resolve returns the path to the audio file. A relative path is resolved
+// against the folder passed in parameter.
+func (m Media) Resolve(baseDir string) string {
+	if filepath.IsAbs(m.Path) {
+		return m.Path
+	}
+	return filepath.Join(baseDir, m.Path)
+}
+
+// FormatOffset returns the offset as a string for the player command. An
+// offset of zero returns an empty string so the argument of the offset is
+// not passed to the player.
+func FormatOffset(offset float64) string {
+	if offset == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(offset, 'f', -1, 64)
+}
+
+// PlayerSettings describes how the audio clips are played during the
+// sessions. The command is a template such as
+// "mpv --really-quiet --start={start} --end={end} {file}".
+type PlayerSettings struct {
+	// Command is the command template. If empty, the clips are not played.
+	Command string
+}
+
+// LoadPlayerSettings reads the player settings from the configuration:
+//   player:
+//     command: mpv --really-quiet --start={start} --end={end} {file}
+func LoadPlayerSettings() PlayerSettings {
+	return PlayerSettings{
+		Command: viper.GetString("player.command"),
+	}
+}
+
+// IsEnabled tells if a command is configured to play the clips.
+func (s PlayerSettings) IsEnabled() bool {
+	return s.Command != ""
+}
@@ -0,0 +1,209 @@
+package datamodel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultProfile is the profile used when none was ever chosen. Its
+	// results are stored at the root of the data directory, where they
+	// were before profiles existed.
+	DefaultProfile = "default"
+	// ProfilesDirName is the folder, in the data directory, holding a
+	// folder for each profile other than the default one.
+	ProfilesDirName = "profiles"
+	// LastProfileFileName is the file, in the data directory, storing the
+	// name of the last profile used.
+	LastProfileFileName = "last-profile"
+	// ProfileSettingsFileName is the file, in the folder of a profile,
+	// whose settings override the ones of the configuration file.
+	ProfileSettingsFileName = "settings.yaml"
+)
+
+// profileName restricts the names of the profiles to what can safely be
+// used as a folder name.
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// CheckProfileName returns an error if the name cannot be used for a
+// profile.
+func CheckProfileName(name string) error {
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("%q is not a valid profile name: use letters, digits, dots, dashes and underscores", name)
+	}
+	return nil
+}
+
+// GetProfileDir returns the folder where the results of the profile are
+// stored.
+func GetProfileDir(dataDir string, name string) string {
+	if name == DefaultProfile {
+		return dataDir
+	}
+	return filepath.Join(dataDir, ProfilesDirName, name)
+}
+
+// GetProfileSettingsFile returns the file whose settings override the
+// ones of the configuration file for the profile. The default profile has
+// no such file: it uses the configuration file as is.
+func GetProfileSettingsFile(dataDir string, name string) string {
+	if name == DefaultProfile {
+		return ""
+	}
+	return filepath.Join(GetProfileDir(dataDir, name), ProfileSettingsFileName)
+}
+
+// ListProfiles returns the default profile followed by the other profiles
+// sorted by name.
+func ListProfiles(dataDir string) ([]string, error) {
+	profiles := []string{DefaultProfile}
+	entries, err := ioutil.ReadDir(filepath.Join(dataDir, ProfilesDirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return profiles, nil
+		}
+		return nil, errors.Wrap(err, "failed to list the profiles")
+	}
+	names := []string{}
+	for _, e := range entries {
+		if e.IsDir() && CheckProfileName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return append(profiles, names...), nil
+}
+
+// ProfileExists tells if the profile has been created. The default profile
+// always exists. An error is returned if the name is not valid so it is
+// never used as a path out of the folder of the profiles.
+func ProfileExists(dataDir string, name string) (bool, error) {
+	if name == DefaultProfile {
+		return true, nil
+	}
+	if err := CheckProfileName(name); err != nil {
+		return false, err
+	}
+	exists, err := tools.DirExists(GetProfileDir(dataDir, name))
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to check if profile %q exists", name)
+	}
+	return exists, nil
+}
+
+// CreateProfile creates the folder of the profile with a settings file to
+// fill with the settings specific to the profile.
+func CreateProfile(dataDir string, name string) error {
+	if err := CheckProfileName(name); err != nil {
+		return err
+	}
+	exists, err := ProfileExists(dataDir, name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	settings := []byte(fmt.Sprintf(`# Settings of the profile %s. They override the ones of the configuration
+# file, for instance:
+#   limit: 3
+#   pause:
+#     adaptive: true
+`, name))
+	return tools.SaveBytesToFile(settings, GetProfileSettingsFile(dataDir, name), false)
+}
+
+// DeleteProfile removes the profile and all its results. The default
+// profile cannot be deleted. If the profile was the last one used, the
+// default profile is used next time.
+func DeleteProfile(dataDir string, name string) error {
+	if err := checkProfileCanChange(dataDir, name); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(GetProfileDir(dataDir, name)); err != nil {
+		return errors.Wrapf(err, "failed to delete profile %q", name)
+	}
+	return forgetLastProfile(dataDir, name, DefaultProfile)
+}
+
+// RenameProfile changes the name of the profile, keeping its results and
+// its settings. The default profile cannot be renamed.
+func RenameProfile(dataDir string, name string, newName string) error {
+	if err := checkProfileCanChange(dataDir, name); err != nil {
+		return err
+	}
+	if err := CheckProfileName(newName); err != nil {
+		return err
+	}
+	exists, err := ProfileExists(dataDir, newName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("profile %q already exists", newName)
+	}
+	if err := os.Rename(GetProfileDir(dataDir, name), GetProfileDir(dataDir, newName)); err != nil {
+		return errors.Wrapf(err, "failed to rename profile %q", name)
+	}
+	return forgetLastProfile(dataDir, name, newName)
+}
+
+// checkProfileCanChange returns an error if the name is not valid, if the
+// profile does not exist or is the default one.
+func checkProfileCanChange(dataDir string, name string) error {
+	if err := CheckProfileName(name); err != nil {
+		return err
+	}
+	if name == DefaultProfile {
+		return fmt.Errorf("the profile %q cannot be deleted nor renamed", DefaultProfile)
+	}
+	exists, err := ProfileExists(dataDir, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+	return nil
+}
+
+// forgetLastProfile replaces the last profile used if it is the one passed
+// in parameter.
+func forgetLastProfile(dataDir string, name string, replacement string) error {
+	last, err := LoadLastProfile(dataDir)
+	if err != nil || last != name {
+		return err
+	}
+	return SaveLastProfile(dataDir, replacement)
+}
+
+// LoadLastProfile returns the name of the last profile used. It is the
+// default profile if none was ever chosen.
+func LoadLastProfile(dataDir string) (string, error) {
+	ba, err := ioutil.ReadFile(filepath.Join(dataDir, LastProfileFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultProfile, nil
+		}
+		return DefaultProfile, errors.Wrap(err, "failed to read the last profile used")
+	}
+	name := strings.TrimSpace(string(ba))
+	if CheckProfileName(name) != nil {
+		return DefaultProfile, nil
+	}
+	return name, nil
+}
+
+// SaveLastProfile remembers the profile so it is used by default next
+// time.
+func SaveLastProfile(dataDir string, name string) error {
+	return tools.SaveBytesToFile([]byte(name+"\n"), filepath.Join(dataDir, LastProfileFileName), true)
+}
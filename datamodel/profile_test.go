@@ -0,0 +1,99 @@
+package datamodel
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	dataDir := t.TempDir()
+	if last, err := LoadLastProfile(dataDir); err != nil || last != DefaultProfile {
+		t.Errorf("The default profile must be used when none was chosen. Got %q (err: %v)", last, err)
+	}
+	for _, name := range []string{"tom", "anna"} {
+		if err := CreateProfile(dataDir, name); err != nil {
+			t.Fatalf("creating profile %q must not fail. Received: %v", name, err)
+		}
+	}
+	if err := CreateProfile(dataDir, "anna"); err == nil {
+		t.Errorf("Creating a profile twice must fail")
+	}
+	if err := CreateProfile(dataDir, "../escape"); err == nil {
+		t.Errorf("A profile name must not be a path")
+	}
+	profiles, _ := ListProfiles(dataDir)
+	if strings.Join(profiles, ",") != "default,anna,tom" {
+		t.Errorf("Expected the default profile then the others sorted but got %v", profiles)
+	}
+	if GetProfileDir(dataDir, DefaultProfile) != dataDir {
+		t.Errorf("The default profile must store its results at the root of the data directory")
+	}
+
+	if err := SaveLastProfile(dataDir, "tom"); err != nil {
+		t.Fatalf("saving the last profile must not fail. Received: %v", err)
+	}
+	if err := RenameProfile(dataDir, "tom", "anna"); err == nil {
+		t.Errorf("Renaming a profile to an existing one must fail")
+	}
+	if err := RenameProfile(dataDir, "tom", "thomas"); err != nil {
+		t.Fatalf("renaming a profile must not fail. Received: %v", err)
+	}
+	if last, _ := LoadLastProfile(dataDir); last != "thomas" {
+		t.Errorf("The last profile must follow the rename. Got %q", last)
+	}
+	if exists, _ := ProfileExists(dataDir, "tom"); exists {
+		t.Errorf("The old name of a renamed profile must not exist anymore")
+	}
+
+	if err := DeleteProfile(dataDir, DefaultProfile); err == nil {
+		t.Errorf("The default profile must not be deleted")
+	}
+	if err := DeleteProfile(dataDir, "thomas"); err != nil {
+		t.Fatalf("deleting a profile must not fail. Received: %v", err)
+	}
+	if last, _ := LoadLastProfile(dataDir); last != DefaultProfile {
+		t.Errorf("The default profile must be used once the last one is deleted. Got %q", last)
+	}
+	if err := DeleteProfile(dataDir, "thomas"); err == nil {
+		t.Errorf("Deleting a profile that does not exist must fail")
+	}
+}
+
+// TestProfileNamesAreNotPaths checks that a profile name can never point
+// out of the folder of the profiles, whatever the operation.
+func TestProfileNamesAreNotPaths(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := CreateProfile(dataDir, "anna"); err != nil {
+		t.Fatalf("creating a profile must not fail. Received: %v", err)
+	}
+	history := filepath.Join(dataDir, HistoryFileName)
+	if err := ioutil.WriteFile(history, []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write the history: %v", err)
+	}
+	for _, name := range []string{"..", ".", "../..", "anna/..", "../escape", "a/b", `a\b`, ""} {
+		if err := CheckProfileName(name); err == nil {
+			t.Errorf("The profile name %q must be rejected", name)
+		}
+		if _, err := ProfileExists(dataDir, name); err == nil {
+			t.Errorf("Checking if the profile %q exists must fail", name)
+		}
+		if err := DeleteProfile(dataDir, name); err == nil {
+			t.Errorf("Deleting the profile %q must fail", name)
+		}
+		if err := RenameProfile(dataDir, name, "renamed"); err == nil {
+			t.Errorf("Renaming the profile %q must fail", name)
+		}
+		if err := RenameProfile(dataDir, "anna", name); err == nil {
+			t.Errorf("Renaming a profile to %q must fail", name)
+		}
+	}
+	if _, err := os.Stat(history); err != nil {
+		t.Errorf("The data directory must be left untouched. Received: %v", err)
+	}
+	if exists, _ := ProfileExists(dataDir, "anna"); !exists {
+		t.Errorf("The profile must be left untouched")
+	}
+}
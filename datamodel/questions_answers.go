@@ -1,17 +1,48 @@
 package datamodel
 
+// EntryKind tells the nature of an entry: a single word or expression, or
+// a whole sentence.
+type EntryKind int
+
+const (
+	// Vocabulary is an entry coming from a lesson section
+	Vocabulary EntryKind = iota
+	// Sentence is an entry coming from a sentences section
+	Sentence
+)
+
 // QuestionsAnswers is a datastructure to store questions and their matching
 // answers. The answers[i] matches questions[i].
 type QuestionsAnswers struct {
 	questions []string
 	answers   []string
+	kinds     []EntryKind
+	media     []*Media
+	clozes    []string
+	variants  [][]string
+	scrambled [][]string
+	tags      [][]string
+	words     []*WordInfo
+	// identifiers set by the author, empty when they are not set
+	ids []string
+	// difficulty set by the author, 0 when it is not set
+	difficulties []int
 }
 
 // NewQA builds an empty set of questions/answers.
 func NewQA() QuestionsAnswers {
 	return QuestionsAnswers{
-		questions: []string{},
-		answers:   []string{},
+		questions:    []string{},
+		answers:      []string{},
+		kinds:        []EntryKind{},
+		media:        []*Media{},
+		clozes:       []string{},
+		variants:     [][]string{},
+		scrambled:    [][]string{},
+		tags:         [][]string{},
+		words:        []*WordInfo{},
+		ids:          []string{},
+		difficulties: []int{},
 	}
 }
 
@@ -25,6 +56,47 @@ func (qa QuestionsAnswers) GetAnswer(i int) string {
 	return qa.answers[i]
 }
 
+// GetCloze returns the answer of the i-th entry with its cloze deletions
+// markup, as written in the lessons file. It returns an empty string if the
+// answer has no markup.
+func (qa QuestionsAnswers) GetCloze(i int) string {
+	return qa.clozes[i]
+}
+
+// SetCloze records the answer of the i-th entry with its cloze deletions
+// markup. See ParseClozes for the syntax.
+func (qa *QuestionsAnswers) SetCloze(i int, marked string) {
+	qa.clozes[i] = marked
+}
+
+// GetKind returns the nature of the i-th entry.
+func (qa QuestionsAnswers) GetKind(i int) EntryKind {
+	return qa.kinds[i]
+}
+
+// GetKey returns a key that identifies the i-th entry. It is the
+// identifier set by the author if any. Else the key is built from the
+// question and the answer so it remains the same from one session to
+// another as long as the entry is not modified in the file.
+func (qa QuestionsAnswers) GetKey(i int) string {
+	if qa.ids[i] != "" {
+		return qa.ids[i]
+	}
+	return qa.questions[i] + DefaultQaSep + qa.answers[i]
+}
+
+// GetID returns the identifier set by the author for the i-th entry. It
+// returns an empty string if there is none.
+func (qa QuestionsAnswers) GetID(i int) string {
+	return qa.ids[i]
+}
+
+// SetID records the identifier of the i-th entry. The results of the entry
+// are then kept under this identifier, even if its text changes.
+func (qa *QuestionsAnswers) SetID(i int, ID string) {
+	qa.ids[i] = ID
+}
+
 // GetCount returns the number of entries for the questions.
 func (qa QuestionsAnswers) GetCount() int {
 	return len(qa.questions)
@@ -32,8 +104,91 @@ func (qa QuestionsAnswers) GetCount() int {
 
 // AddEntry adds a set of question/answer to the already existing set.
 func (qa *QuestionsAnswers) AddEntry(q string, a string) {
+	qa.AddEntryOfKind(q, a, Vocabulary)
+}
+
+// AddEntryOfKind is the same as AddEntry but the nature of the entry is
+// set explicitly.
+func (qa *QuestionsAnswers) AddEntryOfKind(q string, a string, kind EntryKind) {
 	qa.questions = append(qa.questions, q)
 	qa.answers = append(qa.answers, a)
+	qa.kinds = append(qa.kinds, kind)
+	qa.media = append(qa.media, nil)
+	qa.clozes = append(qa.clozes, "")
+	qa.variants = append(qa.variants, nil)
+	qa.scrambled = append(qa.scrambled, nil)
+	qa.tags = append(qa.tags, nil)
+	qa.words = append(qa.words, nil)
+	qa.ids = append(qa.ids, "")
+	qa.difficulties = append(qa.difficulties, 0)
+}
+
+// GetVariants returns the acceptable variants of the answer of the i-th
+// entry. The answer itself is not part of the list.
+func (qa QuestionsAnswers) GetVariants(i int) []string {
+	return qa.variants[i]
+}
+
+// SetVariants records the acceptable variants of the answer of the i-th
+// entry.
+func (qa *QuestionsAnswers) SetVariants(i int, variants []string) {
+	qa.variants[i] = variants
+}
+
+// GetScrambledWords returns the shuffled words of the i-th entry if it is a
+// word order exercise. It returns nil otherwise.
+func (qa QuestionsAnswers) GetScrambledWords(i int) []string {
+	return qa.scrambled[i]
+}
+
+// SetScrambledWords records the shuffled words of a word order exercise.
+func (qa *QuestionsAnswers) SetScrambledWords(i int, words []string) {
+	qa.scrambled[i] = words
+}
+
+// GetTags returns the tags of the i-th entry, in lower case.
+func (qa QuestionsAnswers) GetTags(i int) []string {
+	return qa.tags[i]
+}
+
+// SetTags records the tags of the i-th entry. They are stored in lower
+// case, without duplicates.
+func (qa *QuestionsAnswers) SetTags(i int, tags []string) {
+	qa.tags[i] = NormalizeTags(tags)
+}
+
+// GetDifficulty returns the difficulty of the i-th entry set by the author
+// of the lessons, between MinDifficulty and MaxDifficulty. It returns 0 if
+// it is not set.
+func (qa QuestionsAnswers) GetDifficulty(i int) int {
+	return qa.difficulties[i]
+}
+
+// SetDifficulty records the difficulty of the i-th entry.
+func (qa *QuestionsAnswers) SetDifficulty(i int, difficulty int) {
+	qa.difficulties[i] = difficulty
+}
+
+// GetWordInfo returns the grammatical information of the i-th entry. It
+// returns nil if there is none.
+func (qa QuestionsAnswers) GetWordInfo(i int) *WordInfo {
+	return qa.words[i]
+}
+
+// SetWordInfo records the grammatical information of the i-th entry.
+func (qa *QuestionsAnswers) SetWordInfo(i int, w *WordInfo) {
+	qa.words[i] = w
+}
+
+// GetMedia returns the audio clip attached to the i-th entry. It returns
+// nil if there is none.
+func (qa QuestionsAnswers) GetMedia(i int) *Media {
+	return qa.media[i]
+}
+
+// SetMedia attaches an audio clip to the i-th entry.
+func (qa *QuestionsAnswers) SetMedia(i int, m *Media) {
+	qa.media[i] = m
 }
 
 // Concatenate adds the entries of the parameter to an existing QA set.
@@ -44,6 +199,85 @@ func (qa *QuestionsAnswers) Concatenate(qaToAdd ...QuestionsAnswers) {
 		if count > 0 {
 			qa.questions = append(qa.questions, toAdd.questions...)
 			qa.answers = append(qa.answers, toAdd.answers...)
+			qa.kinds = append(qa.kinds, toAdd.kinds...)
+			qa.media = append(qa.media, toAdd.media...)
+			qa.clozes = append(qa.clozes, toAdd.clozes...)
+			qa.variants = append(qa.variants, toAdd.variants...)
+			qa.scrambled = append(qa.scrambled, toAdd.scrambled...)
+			qa.tags = append(qa.tags, toAdd.tags...)
+			qa.words = append(qa.words, toAdd.words...)
+			qa.ids = append(qa.ids, toAdd.ids...)
+			qa.difficulties = append(qa.difficulties, toAdd.difficulties...)
 		}
 	}
 }
+
+// Filter returns the entries whose tags match the filter. All the entries
+// are returned if the filter is empty.
+func (qa QuestionsAnswers) Filter(f TagFilter) QuestionsAnswers {
+	if f.IsEmpty() {
+		return qa
+	}
+	return qa.keep(func(i int) bool {
+		return f.Matches(qa.tags[i])
+	})
+}
+
+// FilterOnDifficulty returns the entries whose difficulty is in the range.
+// All the entries are returned if the range is empty.
+func (qa QuestionsAnswers) FilterOnDifficulty(r DifficultyRange) QuestionsAnswers {
+	if r.IsEmpty() {
+		return qa
+	}
+	return qa.keep(func(i int) bool {
+		return r.Matches(qa.difficulties[i])
+	})
+}
+
+// keep returns the entries for which selected returns true.
+func (qa QuestionsAnswers) keep(selected func(i int) bool) QuestionsAnswers {
+	filtered := NewQA()
+	for i := 0; i < qa.GetCount(); i++ {
+		if !selected(i) {
+			continue
+		}
+		filtered.AddEntryOfKind(qa.questions[i], qa.answers[i], qa.kinds[i])
+		last := filtered.GetCount() - 1
+		filtered.media[last] = qa.media[i]
+		filtered.clozes[last] = qa.clozes[i]
+		filtered.variants[last] = qa.variants[i]
+		filtered.scrambled[last] = qa.scrambled[i]
+		filtered.tags[last] = qa.tags[i]
+		filtered.words[last] = qa.words[i]
+		filtered.ids[last] = qa.ids[i]
+		filtered.difficulties[last] = qa.difficulties[i]
+	}
+	return filtered
+}
+
+// Direction tells which column of an entry is used as the prompt.
+type Direction int
+
+const (
+	// Production asks the question column, written in the native language,
+	// and expects the answer in the learnt language.
+	Production Direction = iota
+	// Recognition asks the answer column, written in the learnt language,
+	// and expects the answer in the native language.
+	Recognition
+)
+
+// String returns the name of the direction.
+func (d Direction) String() string {
+	if d == Recognition {
+		return "recognition"
+	}
+	return "production"
+}
+
+// GetDirectionalKey returns a key that identifies the i-th entry asked in
+// the given direction. Knowing an entry in one direction does not mean
+// knowing it in the other so the results are recorded separately.
+func (qa QuestionsAnswers) GetDirectionalKey(i int, d Direction) string {
+	return qa.GetKey(i) + "@" + d.String()
+}
@@ -0,0 +1,71 @@
+package datamodel
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// maxShuffles is the number of attempts made to get an order of the words
+// that differs from the original sentence.
+const maxShuffles = 10
+
+// ScrambleWords returns the words of the sentence in a random order. When
+// possible, the order differs from the original one.
+func ScrambleWords(rng *rand.Rand, sentence string) []string {
+	words := strings.Fields(sentence)
+	shuffled := make([]string, len(words))
+	copy(shuffled, words)
+	for attempt := 0; attempt < maxShuffles; attempt++ {
+		rng.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		if strings.Join(shuffled, " ") != strings.Join(words, " ") {
+			break
+		}
+	}
+	return shuffled
+}
+
+// FormatScrambledWords numbers the shuffled words so the user can rebuild
+// the sentence by typing the numbers: "[1] dort [2] Le [3] chat".
+func FormatScrambledWords(words []string) string {
+	numbered := make([]string, len(words))
+	for i, w := range words {
+		numbered[i] = fmt.Sprintf("[%d] %s", i+1, w)
+	}
+	return strings.Join(numbered, " ")
+}
+
+// BuildScrambleQuestionsSet creates a set of word order exercises from the
+// sentences of the lessons passed in parameter. The question shows the
+// words of the sentence in the learnt language in a random order, with the
+// translation. The answer is the sentence, and its variants are accepted
+// too. If no lesson is supplied, all the sentences are used.
+func (topic Topic) BuildScrambleQuestionsSet(rng *rand.Rand, ids ...string) QuestionsAnswers {
+	qa := NewQA()
+	var subsections = ids
+	if len(subsections) == 0 {
+		subsections = topic.GetSentencesSubsectionsName()
+		sort.Strings(subsections)
+	}
+	for _, ID := range subsections {
+		sentences := topic.GetSentencesSubsection(ID)
+		for i := 0; i < sentences.GetCount(); i++ {
+			words := ScrambleWords(rng, sentences.GetAnswer(i))
+			if len(words) < 2 {
+				continue
+			}
+			question := fmt.Sprintf("%s (%s)", FormatScrambledWords(words), sentences.GetQuestion(i))
+			qa.AddEntryOfKind(question, sentences.GetAnswer(i), Sentence)
+			last := qa.GetCount() - 1
+			qa.SetScrambledWords(last, words)
+			qa.SetVariants(last, sentences.GetVariants(i))
+			qa.SetMedia(last, sentences.GetMedia(i))
+			qa.SetTags(last, sentences.GetTags(i))
+			qa.SetDifficulty(last, sentences.GetDifficulty(i))
+		}
+	}
+	return qa
+}
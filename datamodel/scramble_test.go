@@ -0,0 +1,29 @@
+package datamodel
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestScrambleWords checks that the shuffled words are the words of the
+// sentence in another order.
+func TestScrambleWords(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sentence := "Le chat dort sur le tapis."
+	words := ScrambleWords(rng, sentence)
+	if strings.Join(words, " ") == sentence {
+		t.Errorf("The words should be shuffled but got the original order")
+	}
+	original := strings.Fields(sentence)
+	sort.Strings(original)
+	shuffled := append([]string{}, words...)
+	sort.Strings(shuffled)
+	if strings.Join(original, " ") != strings.Join(shuffled, " ") {
+		t.Errorf("The shuffled words %v are not the words of %q", words, sentence)
+	}
+	if f := FormatScrambledWords([]string{"dort", "Le", "chat"}); f != "[1] dort [2] Le [3] chat" {
+		t.Errorf("Unexpected formatting of the words: %q", f)
+	}
+}
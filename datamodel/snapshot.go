@@ -0,0 +1,172 @@
+package datamodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/pkg/errors"
+)
+
+// SnapshotFileName is the name of the file, in the data directory, where an
+// interrupted session is saved so it can be resumed.
+const SnapshotFileName = "snapshot.json"
+
+// ItemResult stores the answers given to an item during a session.
+type ItemResult struct {
+	Correct int `json:"correct"`
+	Wrong   int `json:"wrong"`
+}
+
+// Snapshot is the state of an interrupted session. It contains everything
+// needed to rebuild the set of questions and to go on from the question
+// that was interrupted.
+type Snapshot struct {
+	// LessonsFile is the path to the lessons file used by the session
+	LessonsFile string `json:"lessonsFile"`
+	// Checksum of the lessons file when the session was interrupted. It
+	// tells if the file has changed since.
+	Checksum string `json:"checksum"`
+	// Exercise is the kind of questions set: vocabulary, sentences...
+	Exercise    string   `json:"exercise"`
+	Subsections []string `json:"subsections"`
+	// Tags is the filter applied to the entries of the lessons
+	Tags string `json:"tags,omitempty"`
+	// Difficulty is the range of difficulties of the entries asked
+	Difficulty  string            `json:"difficulty,omitempty"`
+	Mode        InterrogationMode `json:"mode"`
+	Interactive bool              `json:"interactive"`
+	Reversed    bool              `json:"reversed"`
+	Mixed       bool              `json:"mixed"`
+	// RecognitionRatio is only meaningful in mixed mode
+	RecognitionRatio float64       `json:"recognitionRatio"`
+	Seed             int64         `json:"seed"`
+	Limit            int           `json:"limit"`
+	MaxDuration      time.Duration `json:"maxDuration"`
+	MaxQuestions     int           `json:"maxQuestions"`
+	// Elapsed is the time spent in the session before the interruption
+	Elapsed time.Duration `json:"elapsed"`
+	// Loop is the number of loops started
+	Loop int `json:"loop"`
+	// QuestionsAsked is the number of questions asked since the start
+	QuestionsAsked int `json:"questionsAsked"`
+	// Next is the index of the next question in linear mode
+	Next int `json:"next"`
+	// AskedInLoop is the list of the questions already asked in the loop
+	AskedInLoop []int `json:"askedInLoop"`
+	// Results of the session, indexed by the key of the item
+	Results map[string]*ItemResult `json:"results"`
+	// SavedAt is the time of the interruption
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// NewSnapshot creates a snapshot holding the settings of the session
+// described by the parameters. The progress is left to the caller.
+func NewSnapshot(p InterrogationParameters) (Snapshot, error) {
+	checksum, err := ComputeFileChecksum(p.GetLessonsFile())
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{
+		LessonsFile:      p.GetLessonsFile(),
+		Checksum:         checksum,
+		Exercise:         p.GetExercise(),
+		Subsections:      p.GetListOfSubsections(),
+		Tags:             p.tags.String(),
+		Difficulty:       p.difficulty.String(),
+		Mode:             p.mode,
+		Interactive:      p.interactive,
+		Reversed:         p.reversed,
+		Mixed:            p.mixed,
+		RecognitionRatio: p.recognitionRatio,
+		Seed:             p.seed,
+		Limit:            p.limit,
+		MaxDuration:      p.maxDuration,
+		MaxQuestions:     p.maxQuestions,
+		Results:          make(map[string]*ItemResult),
+	}, nil
+}
+
+// ComputeFileChecksum returns the SHA-256 of the content of a file.
+func ComputeFileChecksum(path string) (string, error) {
+	ba, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %q to compute its checksum", path)
+	}
+	sum := sha256.Sum256(ba)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// LoadSnapshot reads the snapshot stored in the file passed in parameter.
+// If the file does not exist, nil is returned.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	exists, err := tools.FileExists(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if snapshot file %q exists", path)
+	}
+	if !exists {
+		return nil, nil
+	}
+	ba, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read snapshot file %q", path)
+	}
+	s := &Snapshot{}
+	err = json.Unmarshal(ba, s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode snapshot file %q", path)
+	}
+	if s.Results == nil {
+		s.Results = make(map[string]*ItemResult)
+	}
+	return s, nil
+}
+
+// Save writes the snapshot to the file passed in parameter. The file is
+// overwritten if it already exists.
+func (s Snapshot) Save(path string) error {
+	ba, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the snapshot")
+	}
+	return tools.SaveBytesToFile(ba, path, true)
+}
+
+// RemoveSnapshot deletes the snapshot file. It is not an error if the file
+// does not exist.
+func RemoveSnapshot(path string) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove snapshot file %q", path)
+	}
+	return nil
+}
+
+// IsStale tells if the lessons file has changed since the snapshot was
+// taken. A session cannot be resumed on a file that has changed since the
+// indices of the questions may not match anymore.
+func (s Snapshot) IsStale() (bool, error) {
+	checksum, err := ComputeFileChecksum(s.LessonsFile)
+	if err != nil {
+		return true, err
+	}
+	return checksum != s.Checksum, nil
+}
+
+// Record stores the result of an answer given during the session.
+func (s *Snapshot) Record(key string, correct bool) {
+	r, ok := s.Results[key]
+	if !ok {
+		r = &ItemResult{}
+		s.Results[key] = r
+	}
+	if correct {
+		r.Correct++
+		return
+	}
+	r.Wrong++
+}
@@ -0,0 +1,62 @@
+package datamodel
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotSaveLoadAndStaleness(t *testing.T) {
+	dir := t.TempDir()
+	lessonsFile := filepath.Join(dir, "lessons.csv")
+	if err := ioutil.WriteFile(lessonsFile, []byte("house;Haus\n"), 0644); err != nil {
+		t.Fatalf("failed to write the lessons file: %v", err)
+	}
+	p := NewInterrogationParameters()
+	p.SetLessonsFile(lessonsFile)
+	p.SetListOfSubsections("01", "02")
+	p.SetExercise("vocabulary")
+	p.SetSeed(42)
+	s, err := NewSnapshot(p)
+	if err != nil {
+		t.Fatalf("creating a snapshot must not fail. Received: %v", err)
+	}
+	s.Next = 3
+	s.AskedInLoop = []int{0, 1, 2}
+	s.Record("house;Haus", false)
+	path := filepath.Join(dir, SnapshotFileName)
+	if err := s.Save(path); err != nil {
+		t.Fatalf("saving the snapshot must not fail. Received: %v", err)
+	}
+
+	loaded, err := LoadSnapshot(path)
+	if err != nil || loaded == nil {
+		t.Fatalf("loading the snapshot must not fail. Received: %v", err)
+	}
+	resumed := NewInterrogationParameters()
+	resumed.ResumeFrom(loaded)
+	if resumed.GetSeed() != 42 || resumed.GetExercise() != "vocabulary" || len(resumed.GetListOfSubsections()) != 2 {
+		t.Errorf("The settings of the session must be restored. Got seed %d, exercise %q, subsections %v",
+			resumed.GetSeed(), resumed.GetExercise(), resumed.GetListOfSubsections())
+	}
+	if r := loaded.Results["house;Haus"]; r == nil || r.Wrong != 1 {
+		t.Errorf("The results of the session must be restored. Got %+v", loaded.Results)
+	}
+	if stale, err := loaded.IsStale(); err != nil || stale {
+		t.Errorf("The snapshot must not be stale when the file is unchanged (err: %v)", err)
+	}
+
+	if err := ioutil.WriteFile(lessonsFile, []byte("house;Haus\ncat;Katze\n"), 0644); err != nil {
+		t.Fatalf("failed to update the lessons file: %v", err)
+	}
+	if stale, _ := loaded.IsStale(); !stale {
+		t.Errorf("The snapshot must be stale once the lessons file has changed")
+	}
+
+	if err := RemoveSnapshot(path); err != nil {
+		t.Errorf("removing the snapshot must not fail. Received: %v", err)
+	}
+	if loaded, _ := LoadSnapshot(path); loaded != nil {
+		t.Errorf("No snapshot is expected once removed")
+	}
+}
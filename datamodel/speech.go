@@ -0,0 +1,77 @@
+package datamodel
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// SpeechLangPlaceholder is replaced, in the speech command template, by
+	// the language of the text to speak.
+	SpeechLangPlaceholder = "{lang}"
+	// SpeechTextPlaceholder is replaced, in the speech command template, by
+	// the text to speak.
+	SpeechTextPlaceholder = "{text}"
+)
+
+// SpeechSettings describes how the questions and the answers are spoken.
+// The command is a template such as "espeak-ng -v {lang} {text}". The
+// placeholders are replaced in each argument of the command so a text
+// containing spaces remains a single argument.
+type SpeechSettings struct {
+	// Enabled tells if the questions and the answers are spoken
+	Enabled bool
+	// Command is the default command template
+	Command string
+	// Commands overrides the default command template for a language
+	Commands map[string]string
+	// Voices maps the language, as written in the header of the lessons
+	// file, to the value that replaces {lang} (espeak-ng expects "fr"
+	// rather than "French" for instance).
+	Voices map[string]string
+}
+
+// LoadSpeechSettings reads the speech settings from the configuration:
+//   speech:
+//     command: espeak-ng -v {lang} {text}
+//     commands:
+//       japanese: say -v Kyoko {text}
+//     voices:
+//       french: fr
+func LoadSpeechSettings() SpeechSettings {
+	return SpeechSettings{
+		Command:  viper.GetString("speech.command"),
+		Commands: viper.GetStringMapString("speech.commands"),
+		Voices:   viper.GetStringMapString("speech.voices"),
+	}
+}
+
+// IsConfigured tells if a command template is set to speak at least one
+// language.
+func (s SpeechSettings) IsConfigured() bool {
+	if s.Command != "" {
+		return true
+	}
+	for _, c := range s.Commands {
+		if c != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCommandFor returns the command template used to speak the language
+// passed in parameter and the value of the {lang} placeholder.
+// The configuration keys are case insensitive so the language is too.
+func (s SpeechSettings) GetCommandFor(lang string) (string, string) {
+	key := strings.ToLower(lang)
+	voice := lang
+	if v, ok := s.Voices[key]; ok {
+		voice = v
+	}
+	if c, ok := s.Commands[key]; ok {
+		return c, voice
+	}
+	return s.Command, voice
+}
@@ -0,0 +1,210 @@
+package datamodel
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MinDifficulty is the difficulty of the easiest entries
+	MinDifficulty = 1
+	// MaxDifficulty is the difficulty of the hardest entries
+	MaxDifficulty = 5
+	// excludedTagPrefix marks a tag the entries must not have in a filter
+	excludedTagPrefix = "!"
+)
+
+// tagName restricts the tags to words so they can be written in a filter
+// on the command line.
+var tagName = regexp.MustCompile(`^[\pL\pN_-]+$`)
+
+// NormalizeTags returns the tags in lower case, without spaces around, nor
+// empty tags nor duplicates, in their original order.
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	normalized := []string{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		normalized = append(normalized, t)
+	}
+	return normalized
+}
+
+// CheckTags returns an error if one of the tags is not a single word.
+func CheckTags(tags []string) error {
+	for _, t := range tags {
+		if !tagName.MatchString(t) {
+			return fmt.Errorf("%q is not a valid tag: use letters, digits, dashes and underscores", t)
+		}
+	}
+	return nil
+}
+
+// CheckDifficulty returns an error if the difficulty is out of range.
+func CheckDifficulty(difficulty int) error {
+	if difficulty < MinDifficulty || difficulty > MaxDifficulty {
+		return fmt.Errorf("the difficulty must be between %d and %d. Received %d", MinDifficulty, MaxDifficulty, difficulty)
+	}
+	return nil
+}
+
+// TagFilter selects the entries on their tags: an entry is selected if it
+// has all the required tags and none of the excluded ones.
+type TagFilter struct {
+	Required []string
+	Excluded []string
+}
+
+// ParseTagFilter reads a filter such as "verb,!irregular": the tags
+// prefixed with ! are excluded, the others are required. An empty string
+// is an empty filter that selects everything.
+func ParseTagFilter(s string) (TagFilter, error) {
+	f := TagFilter{}
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		excluded := strings.HasPrefix(t, excludedTagPrefix)
+		t = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(t, excludedTagPrefix)))
+		if err := CheckTags([]string{t}); err != nil {
+			return TagFilter{}, err
+		}
+		if excluded {
+			f.Excluded = append(f.Excluded, t)
+		} else {
+			f.Required = append(f.Required, t)
+		}
+	}
+	return f, nil
+}
+
+// IsEmpty tells if the filter selects everything.
+func (f TagFilter) IsEmpty() bool {
+	return len(f.Required) == 0 && len(f.Excluded) == 0
+}
+
+// Matches tells if an entry with these tags is selected.
+func (f TagFilter) Matches(tags []string) bool {
+	has := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		has[t] = true
+	}
+	for _, t := range f.Required {
+		if !has[t] {
+			return false
+		}
+	}
+	for _, t := range f.Excluded {
+		if has[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the filter with the syntax of ParseTagFilter.
+func (f TagFilter) String() string {
+	parts := append([]string{}, f.Required...)
+	for _, t := range f.Excluded {
+		parts = append(parts, excludedTagPrefix+t)
+	}
+	return strings.Join(parts, ",")
+}
+
+// DifficultyRange selects the entries on the difficulty set by the author.
+// The zero value is an empty range that selects everything.
+type DifficultyRange struct {
+	Min int
+	Max int
+}
+
+// ParseDifficultyRange reads a range such as "1:3", or a single difficulty
+// such as "2". An empty string is an empty range.
+func ParseDifficultyRange(s string) (DifficultyRange, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return DifficultyRange{}, nil
+	}
+	bounds := strings.Split(s, ":")
+	if len(bounds) > 2 {
+		return DifficultyRange{}, fmt.Errorf("%q is not a range of difficulties such as 1:3", s)
+	}
+	values := make([]int, len(bounds))
+	for i, b := range bounds {
+		d, err := strconv.Atoi(strings.TrimSpace(b))
+		if err != nil {
+			return DifficultyRange{}, fmt.Errorf("%q is not a range of difficulties such as 1:3", s)
+		}
+		if err := CheckDifficulty(d); err != nil {
+			return DifficultyRange{}, err
+		}
+		values[i] = d
+	}
+	r := DifficultyRange{Min: values[0], Max: values[len(values)-1]}
+	if r.Min > r.Max {
+		return DifficultyRange{}, fmt.Errorf("the range of difficulties %q starts after its end", s)
+	}
+	return r, nil
+}
+
+// IsEmpty tells if the range selects everything.
+func (r DifficultyRange) IsEmpty() bool {
+	return r.Min == 0 && r.Max == 0
+}
+
+// Matches tells if an entry with this difficulty is selected. An entry
+// without difficulty is only selected by an empty range.
+func (r DifficultyRange) Matches(difficulty int) bool {
+	return r.IsEmpty() || (difficulty >= r.Min && difficulty <= r.Max)
+}
+
+// String returns the range with the syntax of ParseDifficultyRange.
+func (r DifficultyRange) String() string {
+	if r.IsEmpty() {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", r.Min, r.Max)
+}
+
+// CountTags returns the number of entries of the topic carrying each tag:
+// the vocabulary, the sentences and the cells of the conjugation tables,
+// tagged with their tense. The grammar exercises have no tags.
+func (topic Topic) CountTags() map[string]int {
+	counts := make(map[string]int)
+	sections := []QuestionsAnswers{topic.BuildConjugationQuestionsSet()}
+	for _, vocabulary := range topic.vocabulary {
+		sections = append(sections, vocabulary)
+	}
+	for _, sentences := range topic.sentences {
+		sections = append(sections, sentences)
+	}
+	for _, qa := range sections {
+		for i := 0; i < qa.GetCount(); i++ {
+			for _, t := range qa.GetTags(i) {
+				counts[t]++
+			}
+		}
+	}
+	return counts
+}
+
+// SortTags returns the tags of the counts sorted by name.
+func SortTags(counts map[string]int) []string {
+	tags := make([]string, 0, len(counts))
+	for t := range counts {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,58 @@
+package datamodel
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/viper"
+)
+
+// TimingPolicy computes the time left to the user, in unattended mode,
+// between the question and the answer. The delay grows with the length of
+// the question: reading and translating a long sentence takes more time
+// than a single word.
+type TimingPolicy struct {
+	// Base is the delay applied whatever the length of the question
+	Base time.Duration
+	// PerCharacter is the delay added for each character of the question
+	PerCharacter time.Duration
+	// PerWord is the delay added for each word of the question
+	PerWord time.Duration
+}
+
+// Delay returns the time to wait before revealing the answer of the
+// question passed in parameter.
+func (t TimingPolicy) Delay(question string) time.Duration {
+	chars := utf8.RuneCountInString(question)
+	words := len(strings.Fields(question))
+	return t.Base + time.Duration(chars)*t.PerCharacter + time.Duration(words)*t.PerWord
+}
+
+// DefaultVocabularyTiming is the timing policy used for the vocabulary when
+// the adaptive pause is activated and nothing is configured.
+var DefaultVocabularyTiming = TimingPolicy{
+	Base:         time.Second,
+	PerCharacter: 50 * time.Millisecond,
+}
+
+// DefaultSentencesTiming is the timing policy used for the sentences when
+// the adaptive pause is activated and nothing is configured.
+var DefaultSentencesTiming = TimingPolicy{
+	Base:    time.Second,
+	PerWord: 400 * time.Millisecond,
+}
+
+// LoadTimingPolicy reads a timing policy from the configuration. The keys
+// are the prefix followed by base, perCharacter and perWord. When none of
+// them is set, the fallback is returned.
+func LoadTimingPolicy(prefix string, fallback TimingPolicy) TimingPolicy {
+	if !viper.IsSet(prefix+".base") && !viper.IsSet(prefix+".perCharacter") && !viper.IsSet(prefix+".perWord") {
+		return fallback
+	}
+	return TimingPolicy{
+		Base:         viper.GetDuration(prefix + ".base"),
+		PerCharacter: viper.GetDuration(prefix + ".perCharacter"),
+		PerWord:      viper.GetDuration(prefix + ".perWord"),
+	}
+}
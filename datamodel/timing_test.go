@@ -0,0 +1,44 @@
+package datamodel
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTimingPolicyDelay checks that the delay grows with the length of the
+// question.
+func TestTimingPolicyDelay(t *testing.T) {
+	policy := TimingPolicy{
+		Base:         time.Second,
+		PerCharacter: 10 * time.Millisecond,
+		PerWord:      100 * time.Millisecond,
+	}
+	tests := []struct {
+		question string
+		expected time.Duration
+	}{
+		{question: "", expected: time.Second},
+		{question: "chat", expected: time.Second + 40*time.Millisecond + 100*time.Millisecond},
+		{question: "le chat", expected: time.Second + 70*time.Millisecond + 200*time.Millisecond},
+	}
+	for _, test := range tests {
+		computed := policy.Delay(test.question)
+		if computed != test.expected {
+			t.Errorf("for %q, was expecting %s but received %s", test.question, test.expected, computed)
+		}
+	}
+}
+
+// TestPauseOverridesAdaptivePause checks that setting a fixed pause
+// disables the adaptive pause.
+func TestPauseOverridesAdaptivePause(t *testing.T) {
+	p := NewInterrogationParameters()
+	p.SetAdaptivePause(TimingPolicy{Base: time.Second}, TimingPolicy{Base: 3 * time.Second})
+	if pause := p.GetPauseFor(Sentence, "a sentence"); pause != 3*time.Second {
+		t.Errorf("Sentences should use their own policy. Expected 3s but got %s", pause)
+	}
+	p.SetPauseTime(time.Millisecond)
+	if pause := p.GetPauseFor(Sentence, "a sentence"); pause != time.Millisecond {
+		t.Errorf("A fixed pause must override the adaptive pause. Expected 1ms but got %s", pause)
+	}
+}
@@ -2,6 +2,7 @@ package datamodel
 
 import (
 	"fmt"
+	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
@@ -11,18 +12,32 @@ import (
 
 // Topic represents the list of subsections of the file with the questions
 // attached for that section. Usually, a topic will be a lesson subdivided
-// in vocabulary, grammar, sentences, etc.
+// in vocabulary, grammar, sentences, etc. It is the content model of the
+// whole application: the text format of the lessons file is parsed into a
+// topic and the JSON format (see Language) is converted to and from it.
 type Topic struct {
 	// The language for the original words
 	LearnedLanguage string `json:"learned"`
 	// The language for the translation
 	NativeLanguage string `json:"native"`
+	// Book is the book from which the content was extracted, if any
+	Book Book `json:"book"`
+	// the titles of the lessons by number of lessons
+	titles map[string]Resource
+	// the tags and the difficulty set on the lessons, inherited by their
+	// entries
+	lessonTags         map[string][]string
+	lessonDifficulties map[string]int
 	// the map listing the vocabulary of the lessons
 	// (by number or name of lesson)
 	vocabulary map[string]QuestionsAnswers
 	// the map listing the sentences by number of lessons
 	// or lessons names.
-	sentences       map[string]QuestionsAnswers
+	sentences map[string]QuestionsAnswers
+	// the map listing the grammar rules by number of lessons
+	grammar map[string][]GrammarRule
+	// the map listing the conjugation tables by number of lessons
+	conjugations    map[string][]ConjugationTable
 	vocabularyCount int
 	sentencesCount  int
 }
@@ -32,8 +47,14 @@ type Topic struct {
 // map of questions/answers
 func NewTopic() Topic {
 	return Topic{
-		vocabulary: make(map[string]QuestionsAnswers),
-		sentences:  make(map[string]QuestionsAnswers),
+		vocabulary:   make(map[string]QuestionsAnswers),
+		sentences:    make(map[string]QuestionsAnswers),
+		grammar:      make(map[string][]GrammarRule),
+		conjugations: make(map[string][]ConjugationTable),
+		titles:       make(map[string]Resource),
+
+		lessonTags:         make(map[string][]string),
+		lessonDifficulties: make(map[string]int),
 	}
 }
 
@@ -104,6 +125,7 @@ func (topic Topic) GetVocabularySubsectionsName() []string {
 		for ID := range topic.vocabulary {
 			subsections = append(subsections, ID)
 		}
+		sort.Strings(subsections)
 	}
 	return subsections
 }
@@ -117,6 +139,7 @@ func (topic Topic) GetSentencesSubsectionsName() []string {
 		for ID := range topic.sentences {
 			subsections = append(subsections, ID)
 		}
+		sort.Strings(subsections)
 	}
 	return subsections
 }
@@ -166,9 +189,196 @@ func (topic Topic) BuildSentencesQuestionsSet(ids ...string) QuestionsAnswers {
 	return qa
 }
 
+// BuildLessonsQuestionsSet creates a set of questions where the sentences
+// of each lesson come right after its vocabulary. If no lesson is
+// supplied, all the lessons are taken in order.
+func (topic Topic) BuildLessonsQuestionsSet(ids ...string) QuestionsAnswers {
+	qa := NewQA()
+	var subsections = ids
+	if len(subsections) == 0 {
+		fmt.Println("     *** You supplied no subsection, we take them all ***")
+		subsections = topic.GetAllSubsectionsName()
+	}
+	for _, ID := range subsections {
+		qa.Concatenate(topic.GetVocabularySubsection(ID))
+		if sentences, ok := topic.sentences[ID]; ok {
+			qa.Concatenate(sentences)
+		}
+	}
+
+	return qa
+}
+
+// GetAllSubsectionsName returns the sorted list of the lessons that have
+// some content: a title, vocabulary, sentences, grammar rules or
+// conjugation tables.
+func (topic Topic) GetAllSubsectionsName() []string {
+	seen := make(map[string]bool)
+	subsections := []string{}
+	all := append(topic.GetVocabularySubsectionsName(), topic.GetSentencesSubsectionsName()...)
+	all = append(all, topic.GetGrammarSubsectionsName()...)
+	all = append(all, topic.GetConjugationSubsectionsName()...)
+	for ID := range topic.titles {
+		all = append(all, ID)
+	}
+	for _, ID := range all {
+		if !seen[ID] {
+			seen[ID] = true
+			subsections = append(subsections, ID)
+		}
+	}
+	sort.Strings(subsections)
+	return subsections
+}
+
+// GetLessonTitle returns the title of a lesson. The title is empty if the
+// lesson has none.
+func (topic Topic) GetLessonTitle(ID string) Resource {
+	return topic.titles[ID]
+}
+
+// SetLessonTitle defines the title of a lesson.
+func (topic *Topic) SetLessonTitle(ID string, title Resource) {
+	topic.titles[strings.Trim(ID, " ")] = title
+}
+
+// GetLessonTags returns the tags set on a lesson, in lower case. The
+// entries of the lesson have them in addition to their own.
+func (topic Topic) GetLessonTags(ID string) []string {
+	return topic.lessonTags[ID]
+}
+
+// GetLessonDifficulty returns the difficulty set on a lesson, 0 if it is
+// not set. It is the difficulty of the entries that do not set their own.
+func (topic Topic) GetLessonDifficulty(ID string) int {
+	return topic.lessonDifficulties[ID]
+}
+
+// SetLessonTags records the tags and the difficulty set on a lesson. They
+// are only recorded: the entries of the lesson must already have them.
+func (topic *Topic) SetLessonTags(ID string, tags []string, difficulty int) {
+	ID = strings.Trim(ID, " ")
+	topic.lessonTags[ID] = NormalizeTags(tags)
+	topic.lessonDifficulties[ID] = difficulty
+}
+
+// GetNextLessonNumber returns the number of a new lesson: the one after
+// the highest number of the lessons of the topic. The lessons whose ID is
+// not a number are ignored.
+func (topic Topic) GetNextLessonNumber() int {
+	highest := 0
+	for _, ID := range topic.GetAllSubsectionsName() {
+		if n, err := strconv.Atoi(ID); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest + 1
+}
+
+// FormatLessonID returns the ID of the lesson with the number passed in
+// parameter, as it is written on the command line: 01, 02... 10.
+func FormatLessonID(number int) string {
+	return fmt.Sprintf("%02d", number)
+}
+
+// ToLessonIDs returns the IDs of the lessons with the numbers passed in
+// parameter. See FormatLessonID.
+func ToLessonIDs(numbers []int) []string {
+	IDs := make([]string, len(numbers))
+	for i, number := range numbers {
+		IDs[i] = FormatLessonID(number)
+	}
+	return IDs
+}
+
+// Names of the exercises that can be built from the lessons. They are
+// recorded in the snapshots to rebuild the set of an interrupted session.
+const (
+	// ExerciseVocabulary asks the vocabulary of the lessons
+	ExerciseVocabulary = "vocabulary"
+	// ExerciseSentences asks the sentences of the lessons
+	ExerciseSentences = "sentences"
+	// ExerciseLessons asks the sentences of each lesson after its vocabulary
+	ExerciseLessons = "lessons"
+	// ExerciseCloze asks the words blanked out in the sentences
+	ExerciseCloze = "cloze"
+	// ExerciseScramble asks to rebuild the sentences from their shuffled words
+	ExerciseScramble = "scramble"
+	// ExerciseForms asks the gender, the plural and the irregular forms of
+	// the words
+	ExerciseForms = "forms"
+	// ExerciseGrammar asks the exercises of the grammar rules
+	ExerciseGrammar = "grammar"
+	// ExerciseConjugation asks the cells of the conjugation tables
+	ExerciseConjugation = "conjugation"
+)
+
+// Exercises lists the names of the exercises that can be built from the
+// lessons.
+var Exercises = []string{
+	ExerciseVocabulary,
+	ExerciseSentences,
+	ExerciseLessons,
+	ExerciseCloze,
+	ExerciseScramble,
+	ExerciseForms,
+	ExerciseGrammar,
+	ExerciseConjugation,
+}
+
+// IsExercise tells if the name passed in parameter is one of the
+// Exercises.
+func IsExercise(name string) bool {
+	for _, exercise := range Exercises {
+		if name == exercise {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOneWayExercise tells if the questions of the exercise can only be asked
+// in the production direction. The prompt of an exercise built from the
+// lessons, such as a sentence with a blank or with its words shuffled, is
+// not an answer the user could be asked to find back.
+func IsOneWayExercise(exercise string) bool {
+	switch exercise {
+	case "", ExerciseVocabulary, ExerciseSentences, ExerciseLessons:
+		return false
+	}
+	return true
+}
+
+// BuildExerciseQuestionsSet creates the set of questions of the exercise
+// for the lessons passed in parameter. The random source is used to
+// shuffle the words of the scramble exercise.
+func (topic Topic) BuildExerciseQuestionsSet(exercise string, rng *rand.Rand, ids ...string) QuestionsAnswers {
+	switch exercise {
+	case ExerciseScramble:
+		return topic.BuildScrambleQuestionsSet(rng, ids...)
+	case ExerciseCloze:
+		return topic.BuildClozeQuestionsSet(ids...)
+	case ExerciseForms:
+		return topic.BuildFormsQuestionsSet(ids...)
+	case ExerciseGrammar:
+		return topic.BuildGrammarQuestionsSet(ids...)
+	case ExerciseConjugation:
+		return topic.BuildConjugationQuestionsSet(ids...)
+	case ExerciseSentences:
+		return topic.BuildSentencesQuestionsSet(ids...)
+	case ExerciseLessons:
+		return topic.BuildLessonsQuestionsSet(ids...)
+	default:
+		return topic.BuildVocabularyQuestionsSet(ids...)
+	}
+}
+
 // ShowSummary displays on user what is available  in this topic.
 func (topic Topic) ShowSummary() {
 	tools.WriteInCyan("  Content of the loaded resources\n")
+	if topic.Book.Title != "" {
+		fmt.Printf("    * Book: %s\n", topic.Book)
+	}
 	fmt.Printf("    * Learned: %s\n", topic.LearnedLanguage)
 	fmt.Printf("    * Native: %s\n", topic.NativeLanguage)
 	fmt.Printf("      - Lessons available: %s\n", topic.ComputeLessonsRange())
@@ -178,10 +388,11 @@ func (topic Topic) ShowSummary() {
 // in a topic. Instead of displaying 1, 2, 3, 4 for instance, it will return 1:4.
 // For 1,2,3,4,5,8,9,10 it will return 1:5,8:10
 func (topic Topic) ComputeLessonsRange() string {
-	lessonsID := make([]int, len(topic.vocabulary))
+	all := topic.GetAllSubsectionsName()
+	lessonsID := make([]int, len(all))
 	i := 0
 	var err error
-	for ID := range topic.vocabulary {
+	for _, ID := range all {
 		lessonsID[i], err = strconv.Atoi(ID)
 		if err != nil {
 			tools.Warningf("a lesson is referenced with %q which is not an integer", ID)
@@ -245,6 +456,30 @@ func (topic *Topic) GetNumberOfSentences() int {
 	return topic.sentencesCount
 }
 
+// FindMissingMedia returns the list of the audio files referenced in the
+// topic that cannot be found. Relative paths are resolved against the
+// folder passed in parameter, usually the folder of the lessons file.
+func (topic Topic) FindMissingMedia(baseDir string) []string {
+	missing := []string{}
+	for _, sections := range []map[string]QuestionsAnswers{topic.vocabulary, topic.sentences} {
+		for ID, qa := range sections {
+			for i := 0; i < qa.GetCount(); i++ {
+				m := qa.GetMedia(i)
+				if m == nil {
+					continue
+				}
+				path := m.Resolve(baseDir)
+				exists, err := tools.FileExists(path)
+				if err != nil || !exists {
+					missing = append(missing, fmt.Sprintf("lesson %s: %q references %q which does not exist", strings.Trim(ID, " "), qa.GetQuestion(i), path))
+				}
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
 // String makes a string representation from the object for debug
 // purpose.
 func (topic *Topic) String() string {
@@ -1,6 +1,12 @@
 package datamodel
 
-import "testing"
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
 
 func TestComputeRangesOnArrayOfInts(t *testing.T) {
 	tests := []struct {
@@ -29,3 +35,168 @@ func TestComputeRangesOnArrayOfInts(t *testing.T) {
 	}
 
 }
+
+// TestFindMissingMedia checks that the audio clips referencing files that
+// do not exist are reported.
+func TestFindMissingMedia(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "found.mp3"), []byte{}, 0644); err != nil {
+		t.Fatalf("failed to create the audio file: %v", err)
+	}
+	qa := NewQA()
+	qa.AddEntry("house", "Haus")
+	qa.SetMedia(0, &Media{Path: "found.mp3"})
+	qa.AddEntry("cat", "Katze")
+	qa.SetMedia(1, &Media{Path: "missing.mp3"})
+	qa.AddEntry("dog", "Hund")
+	topic := NewTopic()
+	topic.SetVocabularySubsection("1", qa)
+
+	missing := topic.FindMissingMedia(dir)
+	if len(missing) != 1 {
+		t.Fatalf("Expected 1 missing file but got %d: %v", len(missing), missing)
+	}
+	if !strings.Contains(missing[0], "missing.mp3") {
+		t.Errorf("Expected missing.mp3 to be reported but got %q", missing[0])
+	}
+}
+
+func TestBuildLessonsQuestionsSet(t *testing.T) {
+	topic := NewTopic()
+	for _, ID := range []string{"02", "01"} {
+		words := NewQA()
+		words.AddEntry("word "+ID, "Wort "+ID)
+		topic.SetVocabularySubsection(ID, words)
+		sentences := NewQA()
+		sentences.AddEntryOfKind("sentence "+ID, "Satz "+ID, Sentence)
+		topic.SetSentencesSubsection(ID, sentences)
+	}
+
+	expected := []string{"word 01", "sentence 01", "word 02", "sentence 02"}
+	qa := topic.BuildLessonsQuestionsSet()
+	if qa.GetCount() != len(expected) {
+		t.Fatalf("Expected %d questions but got %d", len(expected), qa.GetCount())
+	}
+	for i, q := range expected {
+		if qa.GetQuestion(i) != q {
+			t.Errorf("Expected question %d to be %q but got %q", i, q, qa.GetQuestion(i))
+		}
+	}
+
+	qa = topic.BuildLessonsQuestionsSet("02")
+	if qa.GetCount() != 2 || qa.GetKind(1) != Sentence {
+		t.Errorf("Expected the sentence of lesson 02 after its word but got %d questions", qa.GetCount())
+	}
+}
+
+// TestFilterOnTags checks that the entries are selected when they have all
+// the required tags and none of the excluded ones.
+func TestFilterOnTags(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("to be", "sein")
+	qa.SetTags(0, []string{"Verb", "irregular"})
+	qa.AddEntry("to play", "spielen")
+	qa.SetTags(1, []string{"verb"})
+	qa.SetDifficulty(1, 2)
+	qa.AddEntry("bread", "Brot")
+	qa.SetTags(2, []string{"food"})
+
+	f, err := ParseTagFilter("verb, !irregular")
+	if err != nil {
+		t.Fatalf("the filter should be valid. Got %v", err)
+	}
+	if f.String() != "verb,!irregular" {
+		t.Errorf("Expected the filter verb,!irregular but got %q", f.String())
+	}
+	filtered := qa.Filter(f)
+	if filtered.GetCount() != 1 || filtered.GetAnswer(0) != "spielen" || filtered.GetDifficulty(0) != 2 {
+		t.Errorf("Expected only spielen with its difficulty but got %d entries", filtered.GetCount())
+	}
+	if qa.Filter(TagFilter{}).GetCount() != 3 {
+		t.Errorf("An empty filter must select all the entries")
+	}
+	if _, err := ParseTagFilter("verb,to be"); err == nil {
+		t.Errorf("A tag with a space must be rejected")
+	}
+}
+
+// TestSubsectionsNameAreSorted checks that the lessons are listed in order
+// whatever the order they were added.
+func TestSubsectionsNameAreSorted(t *testing.T) {
+	topic := NewTopic()
+	for _, ID := range []string{"03", "01", "10", "02"} {
+		topic.SetVocabularySubsection(ID, NewQA())
+		topic.SetSentencesSubsection(ID, NewQA())
+	}
+	expected := "01,02,03,10"
+	if names := strings.Join(topic.GetVocabularySubsectionsName(), ","); names != expected {
+		t.Errorf("Expected the vocabulary lessons %s but got %s", expected, names)
+	}
+	if names := strings.Join(topic.GetSentencesSubsectionsName(), ","); names != expected {
+		t.Errorf("Expected the sentences lessons %s but got %s", expected, names)
+	}
+}
+
+// TestCountTags checks that the cells of the conjugation tables are
+// counted with the tag of their tense.
+func TestCountTags(t *testing.T) {
+	topic := NewTopic()
+	vocabulary := NewQA()
+	vocabulary.AddEntry("to go", "aller")
+	vocabulary.SetTags(0, []string{"verb"})
+	topic.SetVocabularySubsection("01", vocabulary)
+	sentences := NewQA()
+	sentences.AddEntryOfKind("I go.", "Je vais.", Sentence)
+	sentences.SetTags(0, []string{"verb", "present"})
+	topic.SetSentencesSubsection("01", sentences)
+	c, err := NewConjugationTable("aller", "Présent", []string{"je", "tu", "il"}, []string{"vais", "vas", ""})
+	if err != nil {
+		t.Fatalf("the table should be valid. Got %v", err)
+	}
+	topic.AddConjugation("01", c)
+
+	counts := topic.CountTags()
+	expected := map[string]int{"verb": 2, "present": 1, "présent": 2}
+	if len(counts) != len(expected) {
+		t.Errorf("Expected the counts %v but got %v", expected, counts)
+	}
+	for tag, count := range expected {
+		if counts[tag] != count {
+			t.Errorf("Expected %d entries with the tag %q but got %d", count, tag, counts[tag])
+		}
+	}
+}
+
+// TestFilterOnDifficulty checks that only the entries rated in the range
+// are selected.
+func TestFilterOnDifficulty(t *testing.T) {
+	qa := NewQA()
+	for i, d := range []int{1, 3, 5, 0} {
+		qa.AddEntry(strconv.Itoa(i), strconv.Itoa(i))
+		qa.SetDifficulty(i, d)
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "", expected: "0,1,2,3"},
+		{input: "1:3", expected: "0,1"},
+		{input: "5", expected: "2"},
+		{input: " 2 : 4 ", expected: "1"},
+	}
+	for _, test := range tests {
+		r, err := ParseDifficultyRange(test.input)
+		if err != nil {
+			t.Fatalf("the range %q should be valid. Got %v", test.input, err)
+		}
+		filtered := qa.FilterOnDifficulty(r)
+		if computed := strings.Join(filtered.questions, ","); computed != test.expected {
+			t.Errorf("for range %q, was expecting %s but received %s", test.input, test.expected, computed)
+		}
+	}
+	for _, invalid := range []string{"0:3", "3:1", "1:6", "easy", "1:2:3"} {
+		if _, err := ParseDifficultyRange(invalid); err == nil {
+			t.Errorf("The range %q must be rejected", invalid)
+		}
+	}
+}
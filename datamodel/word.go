@@ -0,0 +1,136 @@
+package datamodel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Gender is the grammatical gender of a noun.
+type Gender string
+
+const (
+	// Masculine is the gender of der Hund or le chien
+	Masculine Gender = "m"
+	// Feminine is the gender of die Katze or la maison
+	Feminine Gender = "f"
+	// Neuter is the gender of das Haus
+	Neuter Gender = "n"
+)
+
+// ParseGender reads a gender written m, f, n or masculine, feminine,
+// neuter.
+func ParseGender(s string) (Gender, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "m", "masculine":
+		return Masculine, nil
+	case "f", "feminine":
+		return Feminine, nil
+	case "n", "neuter":
+		return Neuter, nil
+	}
+	return "", fmt.Errorf("%q is not a gender: use m, f or n", s)
+}
+
+// String returns the name of the gender.
+func (g Gender) String() string {
+	switch g {
+	case Masculine:
+		return "masculine"
+	case Feminine:
+		return "feminine"
+	case Neuter:
+		return "neuter"
+	}
+	return ""
+}
+
+// definiteArticles are the articles of the genders in the languages that
+// have them, indexed by the lower case name of the language.
+var definiteArticles = map[string]map[Gender]string{
+	"german":   {Masculine: "der", Feminine: "die", Neuter: "das"},
+	"deutsch":  {Masculine: "der", Feminine: "die", Neuter: "das"},
+	"allemand": {Masculine: "der", Feminine: "die", Neuter: "das"},
+	"french":   {Masculine: "le", Feminine: "la"},
+	"français": {Masculine: "le", Feminine: "la"},
+	"francais": {Masculine: "le", Feminine: "la"},
+	"spanish":  {Masculine: "el", Feminine: "la"},
+	"español":  {Masculine: "el", Feminine: "la"},
+	"espagnol": {Masculine: "el", Feminine: "la"},
+	"italian":  {Masculine: "il", Feminine: "la"},
+	"italiano": {Masculine: "il", Feminine: "la"},
+	"italien":  {Masculine: "il", Feminine: "la"},
+}
+
+// GetArticle returns the definite article of the gender in the language
+// passed in parameter. It returns an empty string if the language is not
+// known.
+func (g Gender) GetArticle(language string) string {
+	return definiteArticles[strings.ToLower(strings.TrimSpace(language))][g]
+}
+
+// WordInfo is the grammatical information of a vocabulary entry in the
+// learnt language. All the fields are optional.
+type WordInfo struct {
+	// PartOfSpeech is noun, verb, adjective...
+	PartOfSpeech string `json:"pos,omitempty"`
+	// Gender is only meaningful for the nouns
+	Gender Gender `json:"gender,omitempty"`
+	// Plural is the plural of a noun
+	Plural string `json:"plural,omitempty"`
+	// Forms are the irregular forms, such as the past tenses of a verb
+	Forms []string `json:"forms,omitempty"`
+}
+
+// IsEmpty tells if no information is set.
+func (w *WordInfo) IsEmpty() bool {
+	return w == nil || (w.PartOfSpeech == "" && w.Gender == "" && w.Plural == "" && len(w.Forms) == 0)
+}
+
+// BuildFormsQuestionsSet creates a set of questions on the grammatical
+// information of the vocabulary of the lessons passed in parameter: the
+// gender and the plural of the nouns and the irregular forms of the words.
+// The gender is answered with the article when the learnt language has
+// them, the name of the gender is accepted too. The words without
+// grammatical information are left out.
+// If no lesson is supplied, all the vocabulary is used.
+func (topic Topic) BuildFormsQuestionsSet(ids ...string) QuestionsAnswers {
+	qa := NewQA()
+	var subsections = ids
+	if len(subsections) == 0 {
+		subsections = topic.GetVocabularySubsectionsName()
+		sort.Strings(subsections)
+	}
+	for _, ID := range subsections {
+		vocabulary := topic.GetVocabularySubsection(ID)
+		for i := 0; i < vocabulary.GetCount(); i++ {
+			w := vocabulary.GetWordInfo(i)
+			if w.IsEmpty() {
+				continue
+			}
+			word := vocabulary.GetAnswer(i)
+			add := func(question, answer string, variants ...string) {
+				qa.AddEntry(question, answer)
+				last := qa.GetCount() - 1
+				qa.SetVariants(last, variants)
+				qa.SetMedia(last, vocabulary.GetMedia(i))
+				qa.SetTags(last, vocabulary.GetTags(i))
+				qa.SetDifficulty(last, vocabulary.GetDifficulty(i))
+			}
+			if w.Gender != "" {
+				if article := w.Gender.GetArticle(topic.LearnedLanguage); article != "" {
+					add(fmt.Sprintf("gender of %s?", word), article, w.Gender.String(), string(w.Gender), article+" "+word)
+				} else {
+					add(fmt.Sprintf("gender of %s?", word), w.Gender.String(), string(w.Gender))
+				}
+			}
+			if w.Plural != "" {
+				add(fmt.Sprintf("plural of %s?", word), w.Plural)
+			}
+			if len(w.Forms) > 0 {
+				add(fmt.Sprintf("forms of %s?", word), strings.Join(w.Forms, ", "), strings.Join(w.Forms, " "))
+			}
+		}
+	}
+	return qa
+}
@@ -0,0 +1,44 @@
+package datamodel
+
+import "testing"
+
+// TestBuildFormsQuestionsSet checks that the gender, the plural and the
+// irregular forms of the words are asked and that the words without
+// grammatical information are left out.
+func TestBuildFormsQuestionsSet(t *testing.T) {
+	topic := NewTopic()
+	topic.LearnedLanguage = "German"
+	qa := NewQA()
+	qa.AddEntry("house", "Haus")
+	qa.SetWordInfo(0, &WordInfo{PartOfSpeech: "noun", Gender: Neuter, Plural: "Häuser"})
+	qa.AddEntry("to go", "gehen")
+	qa.SetWordInfo(1, &WordInfo{PartOfSpeech: "verb", Forms: []string{"ging", "gegangen"}})
+	qa.AddEntry("and", "und")
+	topic.SetVocabularySubsection("1", qa)
+
+	forms := topic.BuildFormsQuestionsSet("1")
+	expected := []struct {
+		question, answer string
+	}{
+		{"gender of Haus?", "das"},
+		{"plural of Haus?", "Häuser"},
+		{"forms of gehen?", "ging, gegangen"},
+	}
+	if forms.GetCount() != len(expected) {
+		t.Fatalf("Expected %d questions but got %d", len(expected), forms.GetCount())
+	}
+	for i, e := range expected {
+		if forms.GetQuestion(i) != e.question || forms.GetAnswer(i) != e.answer {
+			t.Errorf("Expected %q -> %q but got %q -> %q", e.question, e.answer, forms.GetQuestion(i), forms.GetAnswer(i))
+		}
+	}
+	variants := forms.GetVariants(0)
+	if len(variants) == 0 || variants[0] != "neuter" {
+		t.Errorf("The name of the gender must be accepted. Got variants %v", variants)
+	}
+
+	topic.LearnedLanguage = "Japanese"
+	if answer := topic.BuildFormsQuestionsSet("1").GetAnswer(0); answer != "neuter" {
+		t.Errorf("Without articles, the gender must be answered by its name. Got %q", answer)
+	}
+}
@@ -1,14 +1,11 @@
 package engine
 
 import (
-	"bufio"
 	"fmt"
-	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/boris-lenzinger/repeatit/tools"
-	"github.com/spf13/viper"
 
 	"github.com/boris-lenzinger/repeatit/datamodel"
 )
@@ -16,73 +13,129 @@ import (
 // AskQuestions will question the user on the set of questions. The
 // parameter object will supply data to refine the questioning.
 func AskQuestions(qa datamodel.QuestionsAnswers, p datamodel.InterrogationParameters) error {
-	loopsCount, i, idxQuestions := 0, 0, 0
-
-	var wg sync.WaitGroup
-	wg.Add(3)
+	var wg, fanOut sync.WaitGroup
+	wg.Add(1)
+	fanOut.Add(1)
 	nbOfQuestions := qa.GetCount()
 
-	if nbOfQuestions == 0 {
-		return fmt.Errorf("Number of questions is zero. Please check your file")
+	var onEvent func(Event)
+	if addr := p.GetEventsAddress(); addr != "" {
+		events := NewBroadcaster()
+		server, err := serveEvents(addr, events)
+		if err != nil {
+			return err
+		}
+		// the streams end with the session, before the server stops
+		defer server.Close()
+		defer events.Close()
+		onEvent = events.Publish
+		fmt.Fprintf(p.GetOutputStream(), "Streaming the events on http://%s/events\n", server.GetAddr())
+	}
+	session, err := newSession(qa, p, onEvent)
+	if err != nil {
+		return err
+	}
+	if p.IsWeightedMode() && p.IsExplainMode() {
+		explainWeights(p.GetOutputStream(), qa, session.history, explainedDirections(p)...)
+	}
+	if p.IsRandomMode() || p.IsWeightedMode() {
+		// Giving the seed to the user makes it possible to replay the
+		// session in the same order with the --seed flag.
+		fmt.Fprintf(p.GetOutputStream(), "Seed: %d\n", p.GetSeed())
+	}
+	if resumed := p.GetResumedSnapshot(); resumed != nil {
+		fmt.Fprintf(p.GetOutputStream(), "Resuming the session saved on %s after %d questions.\n",
+			resumed.SavedAt.Format(time.RFC1123), resumed.QuestionsAsked)
+	}
+	if err := logSession(p, nbOfQuestions); err != nil {
+		tools.Warning(fmt.Sprintf("the session will not be logged: %v", err))
+	}
+	stopWatching := watchInterrupts(session.progress, p.GetOutputStream(), session.historyFile)
+	defer stopWatching()
+
+	if path := p.GetControlSocket(); path != "" {
+		remote, err := listenForCommands(path, p.Command, p.Replies)
+		if err != nil {
+			return err
+		}
+		defer remote.Close()
+		fmt.Fprintf(p.GetOutputStream(), "Listening for commands on %s\n", path)
 	}
+	input := newUserInput(p)
+	defer input.close()
 
 	// Handling channels in sub-goroutines
-	go fanOutChannel(&wg, p.Qachan, p.Publisher)
-	go publishChanToWriter(&wg, p.Publisher, p.GetOutputStream(), nbOfQuestions, p.GetLimit())
-	go fanOutChannel(&wg, p.Command, p.Publisher)
+	go fanOutChannel(&fanOut, p.Qachan, p.Publisher)
+	go publishChanToWriter(&wg, p.Publisher, p.GetOutputStream(), nbOfQuestions, p.GetLimit(), session.GetQuestionsAsked())
 
-	var question, answer string
-	s := bufio.NewScanner(p.GetInputStream())
-	var indexAlreadyQuestionned map[int]int
 	for {
-		if idxQuestions%nbOfQuestions == 0 {
-			indexAlreadyQuestionned = make(map[int]int)
-			loopsCount++
-			if loopsCount > p.GetLimit() {
-				// if the qa chan is closed, then we have to close the others.
-				close(p.Qachan)
-				close(p.Command)
-				break
-			}
+		card, ok := session.Next()
+		if !ok {
+			break
 		}
-		if p.IsRandomMode() {
-			var present bool
-			for {
-				i = int(rand.Int31n(int32(nbOfQuestions)))
-				if _, present = indexAlreadyQuestionned[i]; !present {
-					break
-				}
-				if present && !viper.GetBool("avoidRepetition") {
-					break
-				}
-				// we need a new randon number...
-			}
+		i := card.Index
+		tools.Debugf("Pushing question %q to qachan", card.Question)
+		questionLang, answerLang := p.GetLanguagesFor(card.Direction)
+		if p.IsListeningOnlyMode() {
+			p.Qachan <- listeningOnlyPrompt
+		} else {
+			p.Qachan <- fmt.Sprintf("%s", withGenderColor(qa, i, card.Direction == datamodel.Recognition, card.Question))
 		}
-		indexAlreadyQuestionned[i] = i
-		question = qa.GetQuestion(i)
-		answer = qa.GetAnswer(i)
-		if p.IsReversedMode() {
-			// user has requested Jeopardy like
-			question = qa.GetAnswer(i)
-			answer = qa.GetQuestion(i)
+		speakOrWarn(p.GetSpeechSettings(), questionLang, card.Question)
+		if card.Direction == datamodel.Recognition {
+			// the clip is in the learnt language which is the question
+			playOrWarn(p.GetPlayerSettings(), qa.GetMedia(i), p.GetMediaDir())
 		}
-		tools.Debugf("Pushing question %q to qachan", question)
-		p.Qachan <- fmt.Sprintf("%s", question)
-		if p.IsInteractive() {
-			if s.Scan() {
-				p.Command <- s.Text()
-			}
-		} else {
-			time.Sleep(p.GetPauseTime())
+		r := input.wait(card)
+		if r.action == quitAction {
+			input.acknowledge(r, nil)
+			break
 		}
-		p.Qachan <- fmt.Sprintf("%s", answer)
-
-		if !p.IsRandomMode() {
-			i = (i + 1) % nbOfQuestions
+		// the answer is revealed anyway: a grade that cannot be recorded
+		// is only reported to the sender
+		var gradeErr error
+		switch r.action {
+		case answerAction:
+			session.Answer(r.attempt)
+		case gradeAction:
+			gradeErr = session.Grade(r.grade)
+		}
+		answer, _ := session.Reveal()
+		p.Qachan <- fmt.Sprintf("%s", withGenderColor(qa, i, card.Direction == datamodel.Production, answer))
+		input.acknowledge(r, gradeErr)
+		speakOrWarn(p.GetSpeechSettings(), answerLang, answer)
+		if card.Direction == datamodel.Production {
+			playOrWarn(p.GetPlayerSettings(), qa.GetMedia(i), p.GetMediaDir())
 		}
-		idxQuestions++
 	}
+	// closing the qa chan stops the fan out to the publisher
+	close(p.Qachan)
+	quit := !session.IsOver()
 
+	// The publisher stops by itself once all the loops are done. When the
+	// session is cut short, closing its channel is the way to stop it.
+	fanOut.Wait()
+	close(p.Publisher)
 	wg.Wait()
-	return nil
+	if p.IsMixedDirectionMode() {
+		session.results.write(p.GetOutputStream())
+	}
+	if session.stoppedBecause != "" {
+		writeCoverageSummary(p.GetOutputStream(), qa, session.covered, session.stoppedBecause)
+	}
+	err = session.Suspend()
+	if quit && session.progress.canBeSaved() && err == nil {
+		fmt.Fprintf(p.GetOutputStream(), "Session saved. Run 'repeatit resume' to go on from here.\n")
+	}
+	return err
+}
+
+// withGenderColor colors the text by the gender of the i-th entry when it
+// is a noun and the text is in the learnt language.
+func withGenderColor(qa datamodel.QuestionsAnswers, i int, isLearntLanguage bool, text string) string {
+	w := qa.GetWordInfo(i)
+	if !isLearntLanguage || w == nil || w.Gender == "" {
+		return text
+	}
+	return tools.ColorByGender(text, string(w.Gender))
 }
@@ -2,8 +2,14 @@ package engine
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
@@ -155,6 +161,7 @@ func validateOutput(tpp datamodel.TopicParsingParameters, questionsSet datamodel
 		isSeparator    bool
 		isNbOfQ        bool
 		isLimitReached bool
+		isSeed         bool
 		expected       string
 		computed       string
 	)
@@ -165,7 +172,8 @@ func validateOutput(tpp datamodel.TopicParsingParameters, questionsSet datamodel
 		isSeparator = tests.Separator.MatchString(s.Text())
 		isNbOfQ = tests.NbOfQuestions.MatchString(s.Text())
 		isLimitReached = tests.LimitReached.MatchString(s.Text())
-		if !isAnnounce && !isEmpty && !isLoop && !isSeparator && !isNbOfQ && !isLimitReached {
+		isSeed = tests.Seed.MatchString(s.Text())
+		if !isAnnounce && !isEmpty && !isLoop && !isSeparator && !isNbOfQ && !isLimitReached && !isSeed {
 			// default is non reverse mode
 			expected = questionsSet.GetQuestion(i) + "     --> " + questionsSet.GetAnswer(i)
 			if reverseMode {
@@ -195,6 +203,7 @@ func validateRandomOutput(tpp datamodel.TopicParsingParameters, questionsSet dat
 		isSeparator    bool
 		isNbOfQ        bool
 		isLimitReached bool
+		isSeed         bool
 		expected       string
 		computed       string
 	)
@@ -205,7 +214,8 @@ func validateRandomOutput(tpp datamodel.TopicParsingParameters, questionsSet dat
 		isSeparator = tests.Separator.MatchString(s.Text())
 		isNbOfQ = tests.NbOfQuestions.MatchString(s.Text())
 		isLimitReached = tests.LimitReached.MatchString(s.Text())
-		if !isAnnounce && !isEmpty && !isLoop && !isSeparator && !isNbOfQ && !isLimitReached {
+		isSeed = tests.Seed.MatchString(s.Text())
+		if !isAnnounce && !isEmpty && !isLoop && !isSeparator && !isNbOfQ && !isLimitReached && !isSeed {
 			// default is non reverse mode
 			expected = questionsSet.GetQuestion(i) + "     --> " + questionsSet.GetQuestion(i)
 			if reverseMode {
@@ -267,3 +277,302 @@ func TestAskQuestionsInLinearAndInteractiveMode(t *testing.T) {
 	validateOutput(tpp, questionsSet, *s, t, ip.IsReversedMode())
 
 }
+
+// TestAskQuestionsWithSeedIsReproducible checks that two random sessions
+// started with the same seed ask the questions in the same order.
+func TestAskQuestionsWithSeedIsReproducible(t *testing.T) {
+	r := strings.NewReader(tests.GetSampleCsvAsStream())
+	topic, err := parsing.ParseTopic(r, datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	questionsSet := topic.BuildVocabularyQuestionsSet()
+
+	outputs := make([]string, 2)
+	for i := 0; i < len(outputs); i++ {
+		var out bytes.Buffer
+		ip := getGenericUnattendedInterrogationParameters()
+		ip.SetRandomMode()
+		ip.SetSeed(42)
+		ip.SetOutputStream(&out)
+		if err := AskQuestions(questionsSet, ip); err != nil {
+			t.Fatalf("asking questions must not fail. Received: %v", err)
+		}
+		outputs[i] = out.String()
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("Two sessions with the same seed must have the same output.\nFirst:\n%s\nSecond:\n%s", outputs[0], outputs[1])
+	}
+	if !strings.Contains(outputs[0], "Seed: 42\n") {
+		t.Errorf("The seed must be displayed at session start. Output:\n%s", outputs[0])
+	}
+}
+
+// TestAskQuestionsWithMaxQuestions checks that a session is cut short once
+// the maximum number of questions is reached and that a summary of what
+// was covered is displayed.
+func TestAskQuestionsWithMaxQuestions(t *testing.T) {
+	r := strings.NewReader(tests.GetSampleCsvAsStream())
+	topic, err := parsing.ParseTopic(r, datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	questionsSet := topic.BuildVocabularyQuestionsSet()
+
+	var out bytes.Buffer
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.SetMaxQuestions(4)
+	ip.SetOutputStream(&out)
+	if err := AskQuestions(questionsSet, ip); err != nil {
+		t.Fatalf("asking questions must not fail. Received: %v", err)
+	}
+	output := out.String()
+	if count := strings.Count(output, "     --> "); count != 4 {
+		t.Errorf("Expected 4 questions to be asked but got %d. Output:\n%s", count, output)
+	}
+	if !strings.Contains(output, "Session stopped: maximum number of questions reached (4)") {
+		t.Errorf("The reason of the stop must be displayed. Output:\n%s", output)
+	}
+	if !strings.Contains(output, "Covered 4 of 6 questions.") {
+		t.Errorf("The coverage must be displayed. Output:\n%s", output)
+	}
+}
+
+func TestAskQuestionsQuitAndResume(t *testing.T) {
+	dir := t.TempDir()
+	lessonsFile := filepath.Join(dir, "lessons.csv")
+	if err := ioutil.WriteFile(lessonsFile, []byte(tests.GetSampleCsvAsStream()), 0644); err != nil {
+		t.Fatalf("failed to write the lessons file: %v", err)
+	}
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	questionsSet := topic.BuildVocabularyQuestionsSet()
+
+	var out bytes.Buffer
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.SetLimit(1)
+	ip.SetLessonsFile(lessonsFile)
+	ip.SetDataDir(dir)
+	ip.SetOutputStream(&out)
+	ip.SetInputStream(strings.NewReader("\n\n" + quitCommand + "\n"))
+	if err := AskQuestions(questionsSet, ip); err != nil {
+		t.Fatalf("asking questions must not fail. Received: %v", err)
+	}
+	snapshot, err := datamodel.LoadSnapshot(ip.GetSnapshotFile())
+	if err != nil || snapshot == nil {
+		t.Fatalf("the session must be saved on %s. Received: %v", quitCommand, err)
+	}
+	if snapshot.QuestionsAsked != 2 || snapshot.Next != 2 || len(snapshot.AskedInLoop) != 2 {
+		t.Errorf("Expected the snapshot to stop after 2 questions but got %+v", snapshot)
+	}
+
+	out.Reset()
+	resumed := getGenericInterrogationParameters()
+	resumed.SetDataDir(dir)
+	resumed.ResumeFrom(snapshot)
+	resumed.SetOutputStream(&out)
+	resumed.SetInputStream(strings.NewReader(strings.Repeat("\n", questionsSet.GetCount())))
+	if err := AskQuestions(questionsSet, resumed); err != nil {
+		t.Fatalf("resuming the session must not fail. Received: %v", err)
+	}
+	output := out.String()
+	if count := strings.Count(output, "     --> "); count != questionsSet.GetCount()-2 {
+		t.Errorf("Expected %d questions to be asked on resume but got %d. Output:\n%s", questionsSet.GetCount()-2, count, output)
+	}
+	if strings.Contains(output, questionsSet.GetQuestion(0)) {
+		t.Errorf("Question %q was already asked before the interruption. Output:\n%s", questionsSet.GetQuestion(0), output)
+	}
+	if snapshot, _ := datamodel.LoadSnapshot(resumed.GetSnapshotFile()); snapshot != nil {
+		t.Errorf("The snapshot must be removed once the resumed session is over")
+	}
+}
+
+func TestAskQuestionsDrivenByTheControlSocket(t *testing.T) {
+	dir := t.TempDir()
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	questionsSet := topic.BuildVocabularyQuestionsSet("02")
+
+	var out bytes.Buffer
+	ip := getGenericUnattendedInterrogationParameters()
+	// only the commands can make the session go on
+	ip.SetPauseTime(time.Hour)
+	ip.SetLimit(1)
+	ip.SetDataDir(dir)
+	ip.SetOutputStream(&out)
+	ip.SetControlSocket(filepath.Join(dir, "control.sock"))
+	done := make(chan error)
+	go func() {
+		done <- AskQuestions(questionsSet, ip)
+	}()
+
+	var conn net.Conn
+	for i := 0; i < 100; i++ {
+		if conn, err = net.Dial("unix", ip.GetControlSocket()); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("the control socket must accept connections. Received: %v", err)
+	}
+	defer conn.Close()
+	if info, err := os.Stat(ip.GetControlSocket()); err != nil {
+		t.Errorf("the control socket must exist. Received: %v", err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("Only the user running the session must access the control socket. Got %v", info.Mode().Perm())
+	}
+	replies := bufio.NewScanner(conn)
+	for _, exchange := range []struct {
+		command string
+		reply   string
+	}{
+		{command: "dance", reply: "error"},
+		{command: "grade 7", reply: "error"},
+		{command: "grade 1", reply: "ok"},
+		{command: "next", reply: "ok"},
+	} {
+		fmt.Fprintln(conn, exchange.command)
+		if !replies.Scan() || !strings.HasPrefix(replies.Text(), exchange.reply) {
+			t.Errorf("Command %q: expected a reply starting with %q but got %q", exchange.command, exchange.reply, replies.Text())
+		}
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("asking questions must not fail. Received: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the session must be over once both questions are answered")
+	}
+	if count := strings.Count(out.String(), "     --> "); count != 2 {
+		t.Errorf("Expected 2 answers to be revealed but got %d. Output:\n%s", count, out.String())
+	}
+	history, err := datamodel.LoadHistory(ip.GetHistoryFile())
+	if err != nil {
+		t.Fatalf("loading the history must not fail. Received: %v", err)
+	}
+	record := history.Items[questionsSet.GetDirectionalKey(0, datamodel.Production)]
+	if record == nil || record.Misses != 1 {
+		t.Errorf("The grade sent on the socket must be recorded as a miss. Got %+v", record)
+	}
+	if _, err := os.Stat(ip.GetControlSocket()); !os.IsNotExist(err) {
+		t.Errorf("The control socket must be removed at the end of the session")
+	}
+}
+
+// TestAskQuestionsLeavesTheTerminalAlone checks that, with a control
+// socket, the terminal is no longer read once the session is over so the
+// next line typed goes to whoever reads it next.
+func TestAskQuestionsLeavesTheTerminalAlone(t *testing.T) {
+	dir := t.TempDir()
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	questionsSet := topic.BuildVocabularyQuestionsSet("02")
+
+	userIn, userOut := io.Pipe()
+	defer userIn.Close()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.SetLimit(1)
+	ip.SetDataDir(dir)
+	ip.SetInputStream(userIn)
+	ip.SetOutputStream(ioutil.Discard)
+	ip.SetControlSocket(filepath.Join(dir, "control.sock"))
+	done := make(chan error)
+	go func() {
+		done <- AskQuestions(questionsSet, ip)
+	}()
+	for i := 0; i < questionsSet.GetCount(); i++ {
+		fmt.Fprintln(userOut)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("asking questions must not fail. Received: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the session must be over once the questions are answered")
+	}
+
+	typed := make(chan struct{})
+	go func() {
+		fmt.Fprintln(userOut, "next command")
+		close(typed)
+	}()
+	select {
+	case <-typed:
+		t.Errorf("The line typed after the session must not be read by the session")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// TestAskQuestionsStreamsTheEvents checks that the events of a session run
+// in the terminal are streamed to the subscribers of the events address.
+func TestAskQuestionsStreamsTheEvents(t *testing.T) {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	questionsSet := topic.BuildVocabularyQuestionsSet("02")
+	// a free port for the events
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port must not fail. Received: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	userIn, userOut := io.Pipe()
+	defer userIn.Close()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.SetLimit(1)
+	ip.SetDataDir(t.TempDir())
+	ip.SetInputStream(userIn)
+	ip.SetOutputStream(ioutil.Discard)
+	ip.SetEventsAddress(addr)
+	done := make(chan error)
+	go func() {
+		done <- AskQuestions(questionsSet, ip)
+	}()
+
+	var resp *http.Response
+	for i := 0; i < 100; i++ {
+		if resp, err = http.Get("http://" + addr + "/events"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("the events must be streamed. Received: %v", err)
+	}
+	defer resp.Body.Close()
+	go func() {
+		for i := 0; i < questionsSet.GetCount(); i++ {
+			fmt.Fprintln(userOut)
+		}
+	}()
+
+	var kinds []string
+	stream := bufio.NewScanner(resp.Body)
+	for stream.Scan() {
+		if strings.HasPrefix(stream.Text(), "event: ") {
+			kinds = append(kinds, strings.TrimPrefix(stream.Text(), "event: "))
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("asking questions must not fail. Received: %v", err)
+	}
+	if len(kinds) == 0 || kinds[len(kinds)-1] != "session-ended" {
+		t.Errorf("Expected the stream to end with session-ended but got %v", kinds)
+	}
+	if revealed := strings.Count(strings.Join(kinds, ","), "answer-revealed"); revealed != questionsSet.GetCount() {
+		t.Errorf("Expected %d answers to be revealed but got the events %v", questionsSet.GetCount(), kinds)
+	}
+}
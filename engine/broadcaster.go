@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"sync"
+)
+
+// DefaultSubscriptionBuffer is the number of events kept for a subscriber
+// that does not read them fast enough. Past this number, the events are
+// dropped for this subscriber.
+const DefaultSubscriptionBuffer = 64
+
+// Broadcaster sends the events of a session to any number of subscribers.
+// Publishing never blocks: a subscriber that does not keep up loses the
+// events that do not fit in its buffer, the session goes on. Its Publish
+// method can be used as the event callback of a session.
+type Broadcaster struct {
+	sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+// NewBroadcaster creates a broadcaster without subscriber.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subscribers: make(map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving the events published from now on,
+// and the function to call to unsubscribe. The channel is closed when the
+// subscriber unsubscribes or when the broadcaster is closed.
+func (b *Broadcaster) Subscribe(buffer int) (<-chan Event, func()) {
+	c := make(chan Event, buffer)
+	b.Lock()
+	defer b.Unlock()
+	if b.closed {
+		close(c)
+		return c, func() {}
+	}
+	b.subscribers[c] = struct{}{}
+	return c, func() {
+		b.Lock()
+		defer b.Unlock()
+		if _, ok := b.subscribers[c]; ok {
+			delete(b.subscribers, c)
+			close(c)
+		}
+	}
+}
+
+// Publish sends the event to the subscribers that have room for it.
+func (b *Broadcaster) Publish(e Event) {
+	b.Lock()
+	defer b.Unlock()
+	for c := range b.subscribers {
+		select {
+		case c <- e:
+		default:
+			// the subscriber is too slow: the engine does not wait for it
+		}
+	}
+}
+
+// Close closes the channels of all the subscribers. Nothing can be
+// published or subscribed afterwards.
+func (b *Broadcaster) Close() {
+	b.Lock()
+	defer b.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for c := range b.subscribers {
+		close(c)
+	}
+	b.subscribers = nil
+}
+
+// GetSubscribersCount returns the number of subscribers.
+func (b *Broadcaster) GetSubscribersCount() int {
+	b.Lock()
+	defer b.Unlock()
+	return len(b.subscribers)
+}
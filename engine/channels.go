@@ -30,14 +30,20 @@ func fanOutChannel(wg *sync.WaitGroup, readFrom <-chan string, writeTo chan<- st
 }
 
 // publishChanToWriter reads from a channel and writes what is read to the writer
-// passed in parameter.
-func publishChanToWriter(wg *sync.WaitGroup, readFrom <-chan string, out io.Writer, qCount int, maxLoops int) {
+// passed in parameter. alreadyAsked is the number of questions asked before
+// the session was resumed so the loops are counted from where they stopped.
+func publishChanToWriter(wg *sync.WaitGroup, readFrom <-chan string, out io.Writer, qCount int, maxLoops int, alreadyAsked int) {
 	defer wg.Done()
-	itemsRead := 0
-	currentLoop := 0
+	itemsRead := 2 * alreadyAsked
+	currentLoop := alreadyAsked / qCount
 	c := color.New(color.FgBlue).Add(color.Bold)
 
 	fmt.Fprintf(out, "Nb of questions: %d\n", qCount)
+	if itemsRead%(2*qCount) != 0 {
+		// resuming in the middle of a loop
+		currentLoop++
+		fmt.Fprint(out, c.Sprintf("Loop (%d/%d)\n", currentLoop, maxLoops))
+	}
 
 	for {
 		if itemsRead%(2*qCount) == 0 {
@@ -52,7 +58,7 @@ func publishChanToWriter(wg *sync.WaitGroup, readFrom <-chan string, out io.Writ
 		select {
 		case v, ok := <-readFrom:
 			if !ok {
-				fmt.Println("failed to read from channel")
+				// the session was stopped before the end of the loops
 				return
 			}
 			itemsRead++
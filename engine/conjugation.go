@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// tenseResult counts the answers on the cells of the tables of a tense.
+type tenseResult struct {
+	tense     string
+	cells     int
+	practised int
+	answers   int
+	misses    int
+}
+
+// missRate is the share of wrong answers, 0 if nothing was answered.
+func (r tenseResult) missRate() float64 {
+	if r.answers == 0 {
+		return 0
+	}
+	return float64(r.misses) / float64(r.answers)
+}
+
+// computeTenseResults gathers the results recorded on the cells of the
+// conjugation tables of the lessons, tense by tense. The weakest tenses,
+// the ones missed the most often, come first.
+func computeTenseResults(topic datamodel.Topic, history datamodel.History, lessonIDs []string) []tenseResult {
+	byTense := make(map[string]*tenseResult)
+	order := []string{}
+	for _, ID := range lessonIDs {
+		for _, c := range topic.GetConjugations(ID) {
+			r, ok := byTense[c.Tense]
+			if !ok {
+				r = &tenseResult{tense: c.Tense}
+				byTense[c.Tense] = r
+				order = append(order, c.Tense)
+			}
+			qa := c.BuildQuestionsSet()
+			for i := 0; i < qa.GetCount(); i++ {
+				r.cells++
+				seen := false
+				for _, d := range []datamodel.Direction{datamodel.Production, datamodel.Recognition} {
+					if item, ok := history.Items[qa.GetDirectionalKey(i, d)]; ok {
+						seen = true
+						r.answers += item.Asked
+						r.misses += item.Misses
+					}
+				}
+				if seen {
+					r.practised++
+				}
+			}
+		}
+	}
+	results := make([]tenseResult, 0, len(order))
+	for _, tense := range order {
+		results = append(results, *byTense[tense])
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].missRate() > results[j].missRate()
+	})
+	return results
+}
+
+// WriteTenseStats displays the results on the conjugation tables of the
+// lessons, tense by tense, the weakest tenses first. It returns false if
+// the lessons have no conjugation table.
+func WriteTenseStats(out io.Writer, topic datamodel.Topic, history datamodel.History, lessonIDs []string) bool {
+	results := computeTenseResults(topic, history, lessonIDs)
+	if len(results) == 0 {
+		return false
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "  tense\tcells\tpractised\tanswers\tmisses\n")
+	for _, r := range results {
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%d\t%d (%.0f%%)\n", r.tense, r.cells, r.practised, r.answers, r.misses, 100*r.missRate())
+	}
+	w.Flush()
+	return true
+}
@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+func TestWriteTenseStatsListsTheWeakestTensesFirst(t *testing.T) {
+	persons := []string{"je", "nous"}
+	present, _ := datamodel.NewConjugationTable("aller", "présent", persons, []string{"vais", "allons"})
+	futur, _ := datamodel.NewConjugationTable("aller", "futur", persons, []string{"irai", "irons"})
+	topic := datamodel.NewTopic()
+	topic.AddConjugation("1", present)
+	topic.AddConjugation("1", futur)
+
+	history := datamodel.NewHistory()
+	qa := futur.BuildQuestionsSet()
+	history.Record(qa.GetDirectionalKey(1, datamodel.Production), false, 0)
+	qa = present.BuildQuestionsSet()
+	history.Record(qa.GetDirectionalKey(0, datamodel.Production), true, 0)
+
+	var out bytes.Buffer
+	if !WriteTenseStats(&out, topic, history, []string{"1"}) {
+		t.Fatalf("The lesson has conjugation tables")
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "futur") || !strings.Contains(lines[1], "100%") || !strings.Contains(lines[2], "présent") {
+		t.Errorf("Expected futur, missed, before présent. Output:\n%s", out.String())
+	}
+	if WriteTenseStats(&out, topic, history, []string{"2"}) {
+		t.Errorf("The lesson 2 has no conjugation table")
+	}
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// directionResults counts the graded answers of a session for each
+// direction. Knowing a word when reading it (recognition) does not mean
+// being able to produce it (production).
+type directionResults struct {
+	asked   map[datamodel.Direction]int
+	correct map[datamodel.Direction]int
+}
+
+// newDirectionResults creates empty counters.
+func newDirectionResults() directionResults {
+	return directionResults{
+		asked:   make(map[datamodel.Direction]int),
+		correct: make(map[datamodel.Direction]int),
+	}
+}
+
+// record counts a graded answer given in the direction d.
+func (r directionResults) record(d datamodel.Direction, correct bool) {
+	r.asked[d]++
+	if correct {
+		r.correct[d]++
+	}
+}
+
+// write displays the results for each direction. Nothing is written if no
+// answer was graded.
+func (r directionResults) write(out io.Writer) {
+	if len(r.asked) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Results by direction:\n")
+	for _, d := range []datamodel.Direction{datamodel.Production, datamodel.Recognition} {
+		fmt.Fprintf(out, "  * %s: %d/%d correct\n", d, r.correct[d], r.asked[d])
+	}
+}
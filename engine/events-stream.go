@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// eventsShutdownDelay is the time given to the streams to send their last
+// events when the session is over.
+const eventsShutdownDelay = time.Second
+
+// EventMessage is the data of a server-sent event. Only the fields that
+// make sense for the kind of event are set.
+type EventMessage struct {
+	Kind      string `json:"kind"`
+	Loop      int    `json:"loop"`
+	Limit     int    `json:"limit"`
+	Question  string `json:"question,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	Answer    string `json:"answer,omitempty"`
+	Attempt   string `json:"attempt,omitempty"`
+	Correct   *bool  `json:"correct,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// NewEventMessage converts an event of the engine for the stream.
+func NewEventMessage(e Event) EventMessage {
+	msg := EventMessage{
+		Kind:   e.Kind.String(),
+		Loop:   e.Loop,
+		Limit:  e.Limit,
+		Answer: e.Answer,
+		Reason: e.Reason,
+	}
+	switch e.Kind {
+	case QuestionAsked, AnswerGraded, AnswerRevealed:
+		msg.Question = e.Card.Question
+		msg.Direction = e.Card.Direction.String()
+	}
+	if e.Kind == AnswerGraded {
+		correct := e.Correct
+		msg.Attempt = e.Attempt
+		msg.Correct = &correct
+	}
+	return msg
+}
+
+// StreamEvents sends the events of the broadcaster as server-sent events
+// until the broadcaster is closed or the client goes away. The name of
+// each event is its kind: question-asked, answer-graded, answer-revealed,
+// loop-started and session-ended.
+func StreamEvents(w http.ResponseWriter, r *http.Request, b *Broadcaster) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "the connection does not support streaming", http.StatusInternalServerError)
+		return
+	}
+	events, unsubscribe := b.Subscribe(DefaultSubscriptionBuffer)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	// the headers tell the client that it is subscribed
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(NewEventMessage(e))
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Kind, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// eventsServer streams the events of a session run in the terminal on
+// GET /events, for the overlays and second screens.
+type eventsServer struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// serveEvents streams the events published by the broadcaster on the
+// address.
+func serveEvents(addr string, events *Broadcaster) (*eventsServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to listen on %q", addr)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, fmt.Sprintf("%s is not allowed on %s", r.Method, r.URL.Path), http.StatusMethodNotAllowed)
+			return
+		}
+		StreamEvents(w, r, events)
+	})
+	s := &eventsServer{listener: listener, server: &http.Server{Handler: mux}}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// GetAddr returns the address the events are streamed on.
+func (s *eventsServer) GetAddr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server once the streams have sent their last events.
+// They end when the broadcaster is closed.
+func (s *eventsServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), eventsShutdownDelay)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
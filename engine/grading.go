@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// isCorrectAnswer tells if the attempt typed by the user matches the
+// expected answer. The comparison ignores the case and the spaces around
+// and between the words.
+func isCorrectAnswer(attempt, expected string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(attempt), " "), strings.Join(strings.Fields(expected), " "))
+}
+
+// acceptedAnswers returns the answers accepted for the i-th entry asked in
+// the direction passed in parameter. The variants declared in the lessons
+// file are in the learnt language so they are only accepted in the
+// production direction.
+func acceptedAnswers(qa datamodel.QuestionsAnswers, i int, d datamodel.Direction) []string {
+	if d == datamodel.Recognition {
+		return []string{qa.GetQuestion(i)}
+	}
+	return append([]string{qa.GetAnswer(i)}, qa.GetVariants(i)...)
+}
+
+// GradeAttempt tells if the attempt typed by the user is one of the
+// accepted answers of the i-th entry. For a word order exercise, the user
+// can type the numbers of the words instead of the words. For a cloze
+// exercise with several blanks, the words of the blanks can be separated
+// by spaces, commas or the separator shown in the answer.
+func GradeAttempt(qa datamodel.QuestionsAnswers, i int, d datamodel.Direction, attempt string) bool {
+	if words := qa.GetScrambledWords(i); words != nil && d == datamodel.Production {
+		if rebuilt, ok := rebuildFromIndices(words, attempt); ok {
+			attempt = rebuilt
+		}
+	}
+	for _, accepted := range acceptedAnswers(qa, i, d) {
+		if strings.Contains(accepted, datamodel.ClozeSeparator) {
+			if isCorrectAnswer(withoutClozeSeparators(attempt), withoutClozeSeparators(accepted)) {
+				return true
+			}
+		} else if isCorrectAnswer(attempt, accepted) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutClozeSeparators replaces the separators of the words of the
+// blanks by spaces: "dort ... chat" and "dort, chat" become "dort chat".
+func withoutClozeSeparators(answer string) string {
+	answer = strings.Replace(answer, strings.TrimSpace(datamodel.ClozeSeparator), " ", -1)
+	return strings.Replace(answer, ",", " ", -1)
+}
+
+// rebuildFromIndices rebuilds a sentence from the numbers of the shuffled
+// words typed by the user ("2 3 1" or "2,3,1"). The boolean is false if the
+// attempt is not a list of valid numbers.
+func rebuildFromIndices(words []string, attempt string) (string, bool) {
+	fields := strings.FieldsFunc(attempt, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+	if len(fields) == 0 {
+		return "", false
+	}
+	rebuilt := make([]string, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 1 || n > len(words) {
+			return "", false
+		}
+		rebuilt[i] = words[n-1]
+	}
+	return strings.Join(rebuilt, " "), true
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// TestGradeAttempt checks that the variants of an answer are accepted and
+// that a word order exercise can be answered with the numbers of the words.
+func TestGradeAttempt(t *testing.T) {
+	qa := datamodel.NewQA()
+	qa.AddEntryOfKind("[1] dort. [2] Le [3] chat", "Le chat dort.", datamodel.Sentence)
+	qa.SetScrambledWords(0, []string{"dort.", "Le", "chat"})
+	qa.SetVariants(0, []string{"Il dort, le chat."})
+
+	tests := []struct {
+		attempt  string
+		expected bool
+	}{
+		{attempt: "Le chat dort.", expected: true},
+		{attempt: "  le   chat dort. ", expected: true},
+		{attempt: "Il dort, le chat.", expected: true},
+		{attempt: "2 3 1", expected: true},
+		{attempt: "2,3,1", expected: true},
+		{attempt: "1 2 3", expected: false},
+		{attempt: "2 3 4", expected: false},
+		{attempt: "Le chien dort.", expected: false},
+	}
+	for _, test := range tests {
+		computed := GradeAttempt(qa, 0, datamodel.Production, test.attempt)
+		if computed != test.expected {
+			t.Errorf("for attempt %q, was expecting %t but received %t", test.attempt, test.expected, computed)
+		}
+	}
+}
+
+// TestGradeAttemptWithSeveralBlanks checks that the words of the blanks of
+// a cloze exercise can be typed without the separator of the answer.
+func TestGradeAttemptWithSeveralBlanks(t *testing.T) {
+	topic := datamodel.NewTopic()
+	sentences := datamodel.NewQA()
+	sentences.AddEntryOfKind("The cat sleeps on the mat", "Le chat dort sur le tapis", datamodel.Sentence)
+	sentences.SetCloze(0, "Le {{c1::chat}} dort sur le {{c1::tapis}}")
+	topic.SetSentencesSubsection("01", sentences)
+	qa := topic.BuildClozeQuestionsSet("01")
+	if qa.GetCount() != 1 || qa.GetAnswer(0) != "chat ... tapis" {
+		t.Fatalf("Expected one exercise with the answer %q but got %d exercises", "chat ... tapis", qa.GetCount())
+	}
+
+	tests := []struct {
+		attempt  string
+		expected bool
+	}{
+		{attempt: "chat ... tapis", expected: true},
+		{attempt: "chat...tapis", expected: true},
+		{attempt: "chat tapis", expected: true},
+		{attempt: "chat, tapis", expected: true},
+		{attempt: "Chat,tapis", expected: true},
+		{attempt: "tapis chat", expected: false},
+		{attempt: "chat", expected: false},
+		{attempt: "chat ... lit", expected: false},
+	}
+	for _, test := range tests {
+		computed := GradeAttempt(qa, 0, datamodel.Production, test.attempt)
+		if computed != test.expected {
+			t.Errorf("for attempt %q, was expecting %t but received %t", test.attempt, test.expected, computed)
+		}
+	}
+}
+
+func TestMaskAnswer(t *testing.T) {
+	testCases := []struct {
+		answer   string
+		level    int
+		expected string
+	}{
+		{"das Haus", 0, "___ ____"},
+		{"das Haus", 1, "d__ H___"},
+		{"l'été, enfin", 2, "l'é__, en___"},
+		{"cat", 5, "cat"},
+	}
+	for _, tc := range testCases {
+		if got := maskAnswer(tc.answer, tc.level); got != tc.expected {
+			t.Errorf("Hint %d of %q: expected %q but got %q", tc.level, tc.answer, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// WriteGrammar renders the grammar rules of the lessons: their title,
+// their explanation and their examples. It returns the number of exercises
+// of the rules so the caller knows if there is something to drill.
+func WriteGrammar(out io.Writer, topic datamodel.Topic, lessonIDs []string) int {
+	exercises := 0
+	for _, ID := range lessonIDs {
+		for _, rule := range topic.GetGrammar(ID) {
+			title := fmt.Sprintf("Lesson %s - %s", ID, rule.Title)
+			fmt.Fprintf(out, "%s\n%s\n", title, strings.Repeat("=", utf8.RuneCountInString(title)))
+			if rule.Explanation != "" {
+				fmt.Fprintf(out, "%s\n", rule.Explanation)
+			}
+			if len(rule.Examples) > 0 {
+				fmt.Fprintf(out, "Examples:\n")
+				for _, e := range rule.Examples {
+					fmt.Fprintf(out, "  * %s (%s)\n", e.Learning, e.Native)
+				}
+			}
+			fmt.Fprintln(out)
+			exercises += len(rule.Exercises)
+		}
+	}
+	return exercises
+}
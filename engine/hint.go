@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// hintMask replaces the letters that are not revealed by a hint.
+const hintMask = '_'
+
+// Grade is the self-assessment of the user on a question.
+type Grade int
+
+const (
+	// GradeAgain means that the answer was not known
+	GradeAgain Grade = iota + 1
+	// GradeHard means that the answer was found with difficulty
+	GradeHard
+	// GradeGood means that the answer was known
+	GradeGood
+	// GradeEasy means that the answer was obvious
+	GradeEasy
+)
+
+// String returns the name of the grade.
+func (g Grade) String() string {
+	switch g {
+	case GradeAgain:
+		return "again"
+	case GradeHard:
+		return "hard"
+	case GradeGood:
+		return "good"
+	case GradeEasy:
+		return "easy"
+	default:
+		return fmt.Sprintf("grade %d", int(g))
+	}
+}
+
+// maskAnswer hides the letters of the answer except the level first ones
+// of each word. Punctuation and spaces are kept so the user sees the shape
+// of the answer.
+func maskAnswer(answer string, level int) string {
+	var b strings.Builder
+	visible := 0
+	for _, r := range answer {
+		switch {
+		case unicode.IsSpace(r):
+			visible = 0
+			b.WriteRune(r)
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			b.WriteRune(r)
+		case visible < level:
+			visible++
+			b.WriteRune(r)
+		default:
+			b.WriteRune(hintMask)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/tools"
+)
+
+// buildHookCommand builds the command described by a template such as
+// "espeak-ng -v {lang} {text}". The placeholders are replaced in each
+// argument after the template is split so a value containing spaces
+// remains a single argument. An argument with a placeholder replaced by
+// an empty value is dropped: "--start={start}" is not passed when there is
+// no offset. It returns nil if the template is empty.
+func buildHookCommand(template string, placeholders map[string]string) *exec.Cmd {
+	var args []string
+	for _, f := range strings.Fields(template) {
+		dropped := false
+		for placeholder, value := range placeholders {
+			if value == "" && strings.Contains(f, placeholder) {
+				dropped = true
+			}
+			f = strings.Replace(f, placeholder, value, -1)
+		}
+		if !dropped {
+			args = append(args, f)
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return exec.Command(args[0], args[1:]...)
+}
+
+// runHook runs a command built by buildHookCommand and waits for its end.
+// The output of the command is not shown to the user but it is reported
+// in the error if the command fails.
+func runHook(cmd *exec.Cmd) error {
+	if cmd == nil {
+		return nil
+	}
+	_, stderr, _, err := tools.ExecCmdCaptureStreamsNoOutput(*cmd)
+	if err != nil {
+		return fmt.Errorf("command %q failed: %v (%s)", strings.Join(cmd.Args, " "), err, strings.TrimSpace(string(stderr)))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// TestBuildHookCommandWithoutOffsets checks that the arguments of the
+// offsets are not passed to the player when the clip is the whole file.
+func TestBuildHookCommandWithoutOffsets(t *testing.T) {
+	template := "mpv --really-quiet --start={start} --end={end} {file}"
+	for _, tc := range []struct {
+		start, end float64
+		expected   string
+	}{
+		{expected: "mpv --really-quiet lesson 1.mp3"},
+		{start: 12.5, expected: "mpv --really-quiet --start=12.5 lesson 1.mp3"},
+		{start: 3, end: 7.25, expected: "mpv --really-quiet --start=3 --end=7.25 lesson 1.mp3"},
+	} {
+		cmd := buildHookCommand(template, map[string]string{
+			datamodel.MediaFilePlaceholder:  "lesson 1.mp3",
+			datamodel.MediaStartPlaceholder: datamodel.FormatOffset(tc.start),
+			datamodel.MediaEndPlaceholder:   datamodel.FormatOffset(tc.end),
+		})
+		if got := strings.Join(cmd.Args, " "); got != tc.expected {
+			t.Errorf("Offsets %v-%v: expected the command %q but got %q", tc.start, tc.end, tc.expected, got)
+		}
+		if last := cmd.Args[len(cmd.Args)-1]; last != "lesson 1.mp3" {
+			t.Errorf("The file must remain a single argument. Got %q", last)
+		}
+	}
+	if cmd := buildHookCommand("", nil); cmd != nil {
+		t.Errorf("Expected no command for an empty template but got %v", cmd.Args)
+	}
+}
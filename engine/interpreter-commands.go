@@ -0,0 +1,485 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/parsing"
+)
+
+// withSentencesOption is the option of the select command that adds the
+// sentences of each lesson after its vocabulary.
+const withSentencesOption = "--with-sentences"
+
+// settings are the names of the settings that can be changed with set.
+var settings = []string{"reverse", "pause", "limit", "mode", "tags", "difficulty"}
+
+// modes are the interrogation modes that can be chosen with set mode.
+var modes = []string{"linear", "random", "weighted"}
+
+// buildCommands returns the commands supported by the interpreter.
+func (i *interpreter) buildCommands() []command {
+	return []command{
+		{
+			name:     "help",
+			usage:    "help [command]",
+			help:     "Lists the commands or describes the one passed in parameter.",
+			run:      i.help,
+			complete: i.completeCommandName,
+		},
+		{
+			name:  "list",
+			usage: "list",
+			help:  "Lists the lessons available in the file with their title and content.",
+			run:   i.list,
+		},
+		{
+			name:     "select",
+			usage:    "select <lessons> [" + withSentencesOption + "]",
+			help:     "Questions on the vocabulary of the lessons (1:5 or 1,3,7). With " + withSentencesOption + ", the sentences of each lesson come after its vocabulary.",
+			run:      i.selectLessons,
+			complete: i.completeLessons,
+		},
+		{
+			name:     "sentences",
+			usage:    "sentences <lessons>",
+			help:     "Questions on the sentences of the lessons.",
+			run:      i.exercise(datamodel.ExerciseSentences),
+			complete: i.completeLessons,
+		},
+		{
+			name:     "cloze",
+			usage:    "cloze <lessons>",
+			help:     "Fill in the blanks of the sentences of the lessons.",
+			run:      i.exercise(datamodel.ExerciseCloze),
+			complete: i.completeLessons,
+		},
+		{
+			name:     "scramble",
+			usage:    "scramble <lessons>",
+			help:     "Rebuild the sentences of the lessons from their shuffled words.",
+			run:      i.exercise(datamodel.ExerciseScramble),
+			complete: i.completeLessons,
+		},
+		{
+			name:     "forms",
+			usage:    "forms <lessons>",
+			help:     "Asks the gender, the plural and the irregular forms of the words of the lessons.",
+			run:      i.exercise(datamodel.ExerciseForms),
+			complete: i.completeLessons,
+		},
+		{
+			name:     "conjugate",
+			usage:    "conjugate <lessons>",
+			help:     "Asks the cells of the conjugation tables of the lessons. set tags futur restricts the drill to a tense.",
+			run:      i.exercise(datamodel.ExerciseConjugation),
+			complete: i.completeLessons,
+		},
+		{
+			name:     "set",
+			usage:    "set reverse on|off | set pause <duration> | set limit <loops> | set mode " + strings.Join(modes, "|") + " | set tags <filter>|off | set difficulty <min:max>|off",
+			help:     "Changes the settings of the next sessions.",
+			run:      i.set,
+			complete: i.completeSetting,
+		},
+		{
+			name:     "show",
+			usage:    "show | show grammar <lessons>",
+			help:     "Displays the settings of the next sessions or, with grammar, the rules of the lessons before drilling their exercises.",
+			run:      i.show,
+			complete: i.completeShow,
+		},
+		{
+			name:     "stats",
+			usage:    "stats [lessons]",
+			help:     "Displays your results on the lessons, recorded from the answers typed in interactive mode, and on the tenses of their conjugation tables.",
+			run:      i.stats,
+			complete: i.completeLessons,
+		},
+		{
+			name:  "tags",
+			usage: "tags",
+			help:  "Lists the tags of the entries with their number of entries. set tags verb,!irregular then only asks the verbs that are not irregular.",
+			run:   i.listTags,
+		},
+		{
+			name:  "search",
+			usage: "search <text>",
+			help:  "Lists the words and the sentences containing the text.",
+			run:   i.search,
+		},
+		{
+			name:  "let",
+			usage: "let <name> = <value>",
+			help:  "Defines a variable, typically a range of lessons, used later as $name: let week = 1:5 then select $week.",
+			run:   i.let,
+		},
+		{
+			name:  "quit",
+			usage: "quit",
+			help:  "Leaves the interpreter.",
+			run: func(args string) error {
+				return errQuit
+			},
+		},
+	}
+}
+
+// help lists the commands or describes one of them.
+func (i *interpreter) help(args string) error {
+	if args != "" {
+		c, ok := i.findCommand(args)
+		if !ok {
+			return fmt.Errorf("%q is not a command. Use help to get the full list of supported commands", args)
+		}
+		fmt.Fprintf(i.out, "%s\n    %s\n", c.usage, c.help)
+		return nil
+	}
+	w := tabwriter.NewWriter(i.out, 0, 4, 2, ' ', 0)
+	for _, c := range i.commands {
+		fmt.Fprintf(w, "  %s\t%s\n", c.name, c.help)
+	}
+	w.Flush()
+	fmt.Fprintf(i.out, "Use help <command> to get the syntax of a command. Tab completes the commands and the lessons.\n")
+	return nil
+}
+
+// list displays the lessons available.
+func (i *interpreter) list(args string) error {
+	fmt.Fprintf(i.out, "Lessons available: %s\n", i.topic.ComputeLessonsRange())
+	return WriteLessons(i.out, i.topic)
+}
+
+// selectLessons questions the user on the vocabulary of the lessons, and
+// on their sentences if required.
+func (i *interpreter) selectLessons(args string) error {
+	if strings.HasSuffix(args, withSentencesOption) {
+		return i.askOnSelection(datamodel.ExerciseLessons, strings.TrimSpace(strings.TrimSuffix(args, withSentencesOption)))
+	}
+	return i.askOnSelection(datamodel.ExerciseVocabulary, args)
+}
+
+// exercise returns a command running the exercise on the lessons passed
+// in parameter.
+func (i *interpreter) exercise(exercise string) func(args string) error {
+	return func(args string) error {
+		return i.askOnSelection(exercise, args)
+	}
+}
+
+// askOnSelection parses the lessons selected by the user and questions
+// her/him on the set of questions of the exercise for these lessons.
+func (i *interpreter) askOnSelection(exercise string, selected string) error {
+	lessonIDs, err := i.parseLessons(selected)
+	if err != nil {
+		return err
+	}
+	// each session needs its own channels since they are closed at the end
+	p := i.params
+	p.ResetChannels()
+	qa := i.topic.BuildExerciseQuestionsSet(exercise, p.GetRandom(), lessonIDs...).Filter(p.GetTagFilter()).FilterOnDifficulty(p.GetDifficultyRange())
+	p.SetExercise(exercise)
+	p.SetListOfSubsections(lessonIDs...)
+	p.SetLanguages(i.topic.NativeLanguage, i.topic.LearnedLanguage)
+	err = AskQuestions(qa, p)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(i.out, "Session is over...\n")
+	return nil
+}
+
+// parseLessons transforms the serie typed by the user into lessons IDs.
+func (i *interpreter) parseLessons(selected string) ([]string, error) {
+	selectedLessons, err := parsing.ParseNumberSerie(selected)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing the list of lessons: %v", err)
+	}
+	return datamodel.ToLessonIDs(selectedLessons), nil
+}
+
+// set changes a setting of the next sessions.
+func (i *interpreter) set(args string) error {
+	setting, value := splitCommand(args)
+	if value == "" {
+		return fmt.Errorf("usage: %s", i.usageOf("set"))
+	}
+	switch setting {
+	case "reverse":
+		switch value {
+		case "on":
+			i.params.SetReverseMode()
+		case "off":
+			i.params.UnsetReverseMode()
+		default:
+			return fmt.Errorf("reverse must be on or off. Received %q", value)
+		}
+	case "pause":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("the pause must be a duration such as 2s or 500ms. Received %q", value)
+		}
+		if d < 0 {
+			return fmt.Errorf("the pause must not be negative. Received %q", value)
+		}
+		i.params.SetPauseTime(d)
+	case "limit":
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("the limit must be a positive number of loops. Received %q", value)
+		}
+		i.params.SetLimit(limit)
+	case "mode":
+		switch value {
+		case "linear":
+			i.params.SetLinearMode()
+		case "random":
+			i.params.SetRandomMode()
+		case "weighted":
+			i.params.SetWeightedMode()
+		default:
+			return fmt.Errorf("the mode must be one of %s. Received %q", strings.Join(modes, ", "), value)
+		}
+	case "tags":
+		if value == "off" {
+			value = ""
+		}
+		f, err := datamodel.ParseTagFilter(value)
+		if err != nil {
+			return err
+		}
+		i.params.SetTagFilter(f)
+	case "difficulty":
+		if value == "off" {
+			value = ""
+		}
+		r, err := datamodel.ParseDifficultyRange(value)
+		if err != nil {
+			return err
+		}
+		i.params.SetDifficultyRange(r)
+	default:
+		return fmt.Errorf("%q cannot be set. Settings are %s", setting, strings.Join(settings, ", "))
+	}
+	return nil
+}
+
+// show displays the settings of the next sessions or the grammar rules of
+// lessons.
+func (i *interpreter) show(args string) error {
+	if what, lessons := splitCommand(args); what == "grammar" {
+		return i.showGrammar(lessons)
+	} else if what != "" {
+		return fmt.Errorf("usage: %s", i.usageOf("show"))
+	}
+	p := i.params
+	pause := p.GetPauseTime().String()
+	if p.IsAdaptivePause() {
+		pause = "adaptive"
+	}
+	w := tabwriter.NewWriter(i.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "  lessons file\t%s\n", p.GetLessonsFile())
+	fmt.Fprintf(w, "  mode\t%s\n", modeName(p))
+	fmt.Fprintf(w, "  reverse\t%s\n", onOff(p.IsReversedMode()))
+	fmt.Fprintf(w, "  mixed directions\t%s\n", onOff(p.IsMixedDirectionMode()))
+	fmt.Fprintf(w, "  interactive\t%s\n", onOff(p.IsInteractive()))
+	fmt.Fprintf(w, "  pause\t%s\n", pause)
+	fmt.Fprintf(w, "  limit\t%d loops\n", p.GetLimit())
+	if !p.GetTagFilter().IsEmpty() {
+		fmt.Fprintf(w, "  tags\t%s\n", p.GetTagFilter())
+	}
+	if !p.GetDifficultyRange().IsEmpty() {
+		fmt.Fprintf(w, "  difficulty\t%s\n", p.GetDifficultyRange())
+	}
+	if p.GetMaxDuration() > 0 {
+		fmt.Fprintf(w, "  max duration\t%s\n", p.GetMaxDuration())
+	}
+	if p.GetMaxQuestions() > 0 {
+		fmt.Fprintf(w, "  max questions\t%d\n", p.GetMaxQuestions())
+	}
+	fmt.Fprintf(w, "  seed\t%d\n", p.GetSeed())
+	return w.Flush()
+}
+
+// showGrammar renders the grammar rules of the lessons then questions the
+// user on their exercises.
+func (i *interpreter) showGrammar(selected string) error {
+	if selected == "" {
+		return fmt.Errorf("usage: %s", i.usageOf("show"))
+	}
+	lessonIDs, err := i.parseLessons(selected)
+	if err != nil {
+		return err
+	}
+	if WriteGrammar(i.out, i.topic, lessonIDs) == 0 {
+		fmt.Fprintf(i.out, "There is no grammar exercise in the lessons %s.\n", selected)
+		return nil
+	}
+	return i.askOnSelection(datamodel.ExerciseGrammar, selected)
+}
+
+// stats displays the results recorded for the lessons.
+func (i *interpreter) stats(args string) error {
+	historyFile := i.params.GetHistoryFile()
+	if historyFile == "" {
+		return fmt.Errorf("no data directory is set: no result is recorded")
+	}
+	history, err := datamodel.LoadHistory(historyFile)
+	if err != nil {
+		return err
+	}
+	lessonIDs := i.topic.GetAllSubsectionsName()
+	if args != "" {
+		lessonIDs, err = i.parseLessons(args)
+		if err != nil {
+			return err
+		}
+	}
+	writeStats(i.out, i.topic, history, lessonIDs)
+	if args == "" {
+		lessonIDs = i.topic.GetConjugationSubsectionsName()
+	}
+	WriteTenseStats(i.out, i.topic, history, lessonIDs)
+	return nil
+}
+
+// search lists the entries containing the text typed by the user.
+func (i *interpreter) search(args string) error {
+	if args == "" {
+		return fmt.Errorf("usage: %s", i.usageOf("search"))
+	}
+	found := searchTopic(i.topic, args)
+	for _, line := range found {
+		fmt.Fprintln(i.out, line)
+	}
+	fmt.Fprintf(i.out, "%d match(es) for %q\n", len(found), args)
+	return nil
+}
+
+// listTags displays the tags of the entries of the topic.
+func (i *interpreter) listTags(args string) error {
+	counts := i.topic.CountTags()
+	if len(counts) == 0 {
+		fmt.Fprintf(i.out, "No entry has tags. Add them in the lessons file with [tags:verb,irregular].\n")
+		return nil
+	}
+	w := tabwriter.NewWriter(i.out, 0, 4, 2, ' ', 0)
+	for _, t := range datamodel.SortTags(counts) {
+		fmt.Fprintf(w, "  %s\t%d\n", t, counts[t])
+	}
+	return w.Flush()
+}
+
+// let defines a variable.
+func (i *interpreter) let(args string) error {
+	idx := strings.Index(args, "=")
+	if idx == -1 {
+		return fmt.Errorf("usage: %s", i.usageOf("let"))
+	}
+	name, value := strings.TrimSpace(args[:idx]), strings.TrimSpace(args[idx+1:])
+	if !variableName.MatchString(name) {
+		return fmt.Errorf("%q is not a valid variable name: use letters, digits and underscores", name)
+	}
+	i.variables[name] = value
+	return nil
+}
+
+// usageOf returns the syntax of a command.
+func (i *interpreter) usageOf(name string) string {
+	c, _ := i.findCommand(name)
+	return c.usage
+}
+
+// completeCommandName completes the name of a command.
+func (i *interpreter) completeCommandName(args string) []string {
+	candidates := []string{}
+	for _, c := range i.commands {
+		if strings.HasPrefix(c.name, args) {
+			candidates = append(candidates, c.name)
+		}
+	}
+	return candidates
+}
+
+// completeLessons completes the last lesson of a serie such as 1:3,5.
+func (i *interpreter) completeLessons(args string) []string {
+	cut := strings.LastIndexAny(args, ":, ") + 1
+	prefix, typed := args[:cut], args[cut:]
+	candidates := []string{}
+	for _, ID := range i.topic.GetAllSubsectionsName() {
+		if strings.HasPrefix(ID, typed) {
+			candidates = append(candidates, prefix+ID)
+		}
+		// IDs such as 01 are also typed as 1
+		trimmed := strings.TrimLeft(ID, "0")
+		if trimmed != ID && trimmed != "" && typed != "" && strings.HasPrefix(trimmed, typed) {
+			candidates = append(candidates, prefix+trimmed)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// completeShow completes show grammar and its lessons.
+func (i *interpreter) completeShow(args string) []string {
+	if !strings.Contains(args, " ") {
+		if strings.HasPrefix("grammar", args) {
+			return []string{"grammar "}
+		}
+		return []string{}
+	}
+	what, lessons := splitCommand(args)
+	candidates := []string{}
+	if what == "grammar" {
+		for _, c := range i.completeLessons(lessons) {
+			candidates = append(candidates, what+" "+c)
+		}
+	}
+	return candidates
+}
+
+// completeSetting completes the name of a setting and its value.
+func (i *interpreter) completeSetting(args string) []string {
+	candidates := []string{}
+	setting, value := splitCommand(args)
+	if !strings.Contains(args, " ") {
+		for _, s := range settings {
+			if strings.HasPrefix(s, setting) {
+				candidates = append(candidates, s+" ")
+			}
+		}
+		return candidates
+	}
+	var values []string
+	switch setting {
+	case "reverse":
+		values = []string{"on", "off"}
+	case "mode":
+		values = modes
+	case "tags":
+		// only the last tag of the filter is completed
+		cut := strings.LastIndexAny(value, ",!") + 1
+		for _, t := range datamodel.SortTags(i.topic.CountTags()) {
+			values = append(values, value[:cut]+t)
+		}
+	}
+	for _, v := range values {
+		if strings.HasPrefix(v, value) {
+			candidates = append(candidates, setting+" "+v)
+		}
+	}
+	return candidates
+}
+
+// onOff displays a boolean setting.
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
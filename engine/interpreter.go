@@ -2,55 +2,201 @@ package engine
 
 import (
 	"fmt"
-	"math/rand"
+	"io"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
-	"time"
-
-	"github.com/boris-lenzinger/repeatit/parsing"
 
 	"github.com/boris-lenzinger/repeatit/datamodel"
 	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/peterh/liner"
+	"github.com/pkg/errors"
 )
 
-// StartEngine is starting the command interpretor.
-func StartEngine(t datamodel.Topic) {
+// errQuit is returned by the quit command to stop the interpreter.
+var errQuit = fmt.Errorf("exiting on user request")
+
+// interpreter executes the commands typed by the user on a topic. The
+// sessions are started with the parameters of the interpreter so the flags
+// of the command line and the settings changed with set apply.
+type interpreter struct {
+	topic    datamodel.Topic
+	params   datamodel.InterrogationParameters
+	out      io.Writer
+	commands []command
+	// variables defined with let, typically lessons ranges
+	variables map[string]string
+}
+
+// command describes a command of the interpreter.
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(args string) error
+	// complete returns the candidates for the arguments of the command. It
+	// can be nil if the command has no argument.
+	complete func(args string) []string
+}
+
+// newInterpreter creates an interpreter writing to out.
+func newInterpreter(t datamodel.Topic, p datamodel.InterrogationParameters, out io.Writer) *interpreter {
+	i := &interpreter{
+		topic:     t,
+		params:    p,
+		out:       out,
+		variables: make(map[string]string),
+	}
+	i.commands = i.buildCommands()
+	return i
+}
+
+// StartEngine is starting the command interpretor. The sessions use the
+// parameters passed in parameter so the flags of the command line apply.
+// The commands typed are kept in the data directory and can be recalled
+// with the arrow keys. Tab completes the commands and the lessons IDs.
+// If a script is supplied, its commands are run before the prompt is
+// displayed. An error is returned if the script fails.
+func StartEngine(t datamodel.Topic, p datamodel.InterrogationParameters, scriptFile string) error {
 	t.ShowSummary()
-	rand.Seed(time.Now().UTC().UnixNano())
+	i := newInterpreter(t, p, os.Stdout)
+	if scriptFile != "" {
+		err := i.runScriptFile(scriptFile)
+		if err == errQuit {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	line := liner.NewLiner()
+	line.SetCtrlCAborts(true)
+	line.SetCompleter(i.complete)
+	historyFile := p.GetCommandsHistoryFile()
+	loadCommandsHistory(line, historyFile)
 
-loop:
+	var failure error
 	for {
-		tools.WriteInCyan(fmt.Sprintf("> "))
-		userInput := tools.ReadFromStdin(true)
-		switch {
-		case userInput == "":
-			continue
-		case strings.HasPrefix(userInput, "select"):
-			selected := strings.TrimPrefix(userInput, "select")
-			selected = strings.TrimPrefix(selected, " ")
-			selectedLessons, err := parsing.ParseNumberSerie(selected)
-			if err != nil {
-				tools.NegativeStatus(fmt.Sprintf("error while parsing the list of lessons: %v", err))
-				continue
-			}
-			numberOfDigitsForLessonsIDs := len(strconv.Itoa(t.GetVocabularySubsectionsCount()))
-			qa := t.BuildVocabularyQuestionsSet(tools.ConvertToStringsArray(selectedLessons, numberOfDigitsForLessonsIDs)...)
-			interrogParams := datamodel.NewInterrogationParameters()
-			err = AskQuestions(qa, interrogParams)
-			if err != nil {
-				tools.NegativeStatus(fmt.Sprintf("%v", err))
+		userInput, err := line.Prompt("> ")
+		if err != nil {
+			if err != liner.ErrPromptAborted && err != io.EOF {
+				failure = errors.Wrap(err, "failed to read the command")
 			}
-			fmt.Printf("Session is over...\n")
-		case userInput == "list":
-			fmt.Printf("Lessons available: %s\n", t.ComputeLessonsRange())
-		case userInput == "help":
-		case userInput == "quit":
+			break
+		}
+		userInput = strings.TrimSpace(userInput)
+		if userInput == "" {
+			continue
+		}
+		line.AppendHistory(userInput)
+		err = i.execute(userInput)
+		if err == errQuit {
 			fmt.Println("Exiting on user request.")
-			break loop
-		default:
-			tools.NegativeStatus(fmt.Sprintf("%q is an invalid command. Use help to get the full list of supported commands", userInput))
+			break
+		}
+		if err != nil {
+			tools.NegativeStatus(fmt.Sprintf("%v", err))
 		}
 	}
-	os.Exit(1)
+	saveCommandsHistory(line, historyFile)
+	line.Close()
+	return failure
+}
+
+// execute runs the command typed by the user once the variables are
+// replaced by their value.
+func (i *interpreter) execute(userInput string) error {
+	userInput, err := i.expandVariables(userInput)
+	if err != nil {
+		return err
+	}
+	name, args := splitCommand(userInput)
+	c, ok := i.findCommand(name)
+	if !ok {
+		return fmt.Errorf("%q is an invalid command. Use help to get the full list of supported commands", userInput)
+	}
+	return c.run(args)
+}
+
+// findCommand returns the command with the name passed in parameter.
+func (i *interpreter) findCommand(name string) (command, bool) {
+	for _, c := range i.commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// complete returns the lines that complete the one typed by the user.
+func (i *interpreter) complete(line string) []string {
+	candidates := []string{}
+	if !strings.Contains(line, " ") {
+		for _, c := range i.commands {
+			if strings.HasPrefix(c.name, line) {
+				candidates = append(candidates, c.name+" ")
+			}
+		}
+		return candidates
+	}
+	name, _ := splitCommand(line)
+	c, ok := i.findCommand(name)
+	if !ok || c.complete == nil {
+		return candidates
+	}
+	for _, completion := range c.complete(strings.TrimLeft(line[len(name):], " ")) {
+		candidates = append(candidates, name+" "+completion)
+	}
+	return candidates
+}
+
+// splitCommand separates the name of the command from its arguments.
+func splitCommand(userInput string) (string, string) {
+	userInput = strings.TrimSpace(userInput)
+	idx := strings.Index(userInput, " ")
+	if idx == -1 {
+		return userInput, ""
+	}
+	return userInput[:idx], strings.TrimSpace(userInput[idx+1:])
+}
+
+// loadCommandsHistory reads the commands typed in the previous sessions of
+// the interpreter.
+func loadCommandsHistory(line *liner.State, path string) {
+	if path == "" {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			tools.Warning(fmt.Sprintf("failed to read the commands history %q: %v", path, err))
+		}
+		return
+	}
+	defer f.Close()
+	if _, err := line.ReadHistory(f); err != nil {
+		tools.Warning(fmt.Sprintf("failed to read the commands history %q: %v", path, err))
+	}
+}
+
+// saveCommandsHistory keeps the commands typed so they can be recalled in
+// the next sessions of the interpreter.
+func saveCommandsHistory(line *liner.State, path string) {
+	if path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), tools.DefaultPermission); err != nil {
+		tools.Warning(fmt.Sprintf("failed to save the commands history: %v", err))
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		tools.Warning(fmt.Sprintf("failed to save the commands history %q: %v", path, err))
+		return
+	}
+	defer f.Close()
+	if _, err := line.WriteHistory(f); err != nil {
+		tools.Warning(fmt.Sprintf("failed to save the commands history %q: %v", path, err))
+	}
 }
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/internal/tests"
+	"github.com/boris-lenzinger/repeatit/parsing"
+)
+
+func getSampleInterpreter(t *testing.T) (*interpreter, *bytes.Buffer) {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	var out bytes.Buffer
+	return newInterpreter(topic, getGenericInterrogationParameters(), &out), &out
+}
+
+func TestInterpreterSetAndShow(t *testing.T) {
+	i, out := getSampleInterpreter(t)
+	for _, c := range []string{"set reverse on", "set pause 3s", "set limit 4", "set mode weighted", "show"} {
+		if err := i.execute(c); err != nil {
+			t.Fatalf("%q must not fail. Received: %v", c, err)
+		}
+	}
+	if !i.params.IsReversedMode() || !i.params.IsWeightedMode() || i.params.GetLimit() != 4 || i.params.GetPauseTime().String() != "3s" {
+		t.Errorf("The settings must be changed by set")
+	}
+	for _, expected := range []string{"weighted", "reverse", "3s", "4 loops"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("show must display %q. Output:\n%s", expected, out.String())
+		}
+	}
+	for _, c := range []string{"set reverse maybe", "set limit -1", "set pause -2s", "set mode chaos", "set colour blue", "set", "unknown"} {
+		if err := i.execute(c); err == nil {
+			t.Errorf("%q must fail", c)
+		}
+	}
+	if i.params.GetPauseTime().String() != "3s" {
+		t.Errorf("A rejected pause must not change the setting. Got %s", i.params.GetPauseTime())
+	}
+	if err := i.execute("quit"); err != errQuit {
+		t.Errorf("quit must stop the interpreter. Received: %v", err)
+	}
+}
+
+func TestInterpreterSelectUsesItsParameters(t *testing.T) {
+	i, out := getSampleInterpreter(t)
+	i.params.SetLimit(1)
+	i.params.SetOutputStream(out)
+	for _, c := range []string{"select 1", "select 1"} {
+		if err := i.execute(c); err != nil {
+			t.Fatalf("%q must not fail. Received: %v", c, err)
+		}
+	}
+	if count := strings.Count(out.String(), "Session is over..."); count != 2 {
+		t.Errorf("Expected 2 sessions in a row but got %d. Output:\n%s", count, out.String())
+	}
+	if !strings.Contains(out.String(), "Loop (1/1)") {
+		t.Errorf("The limit of the interpreter must be used by the sessions. Output:\n%s", out.String())
+	}
+}
+
+func TestInterpreterComplete(t *testing.T) {
+	i, _ := getSampleInterpreter(t)
+	testCases := []struct {
+		line     string
+		expected []string
+	}{
+		{"se", []string{"select ", "sentences ", "set ", "search "}},
+		{"set m", []string{"set mode "}},
+		{"set mode l", []string{"set mode linear"}},
+		{"help sh", []string{"help show"}},
+		{"quit ", []string{}},
+	}
+	for _, tc := range testCases {
+		got := i.complete(tc.line)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Completion of %q: expected %v but got %v", tc.line, tc.expected, got)
+		}
+	}
+	for _, candidate := range i.complete("select 1,") {
+		if !strings.HasPrefix(candidate, "select 1,") {
+			t.Errorf("Completion of lessons must keep the serie already typed. Got %q", candidate)
+		}
+	}
+}
+
+func TestInterpreterSearch(t *testing.T) {
+	i, out := getSampleInterpreter(t)
+	qa := i.topic.BuildVocabularyQuestionsSet()
+	word := qa.GetAnswer(0)
+	if err := i.execute("search " + strings.ToUpper(word)); err != nil {
+		t.Fatalf("search must not fail. Received: %v", err)
+	}
+	if !strings.Contains(out.String(), word) || strings.Contains(out.String(), "0 match(es)") {
+		t.Errorf("search must find %q regardless of the case. Output:\n%s", word, out.String())
+	}
+}
+
+func TestInterpreterShowGrammar(t *testing.T) {
+	content := `#native;learnt
+### Lesson 01
+to rain;regnen
+### Grammar Lesson 01
+## Word order after weil
+After weil, the conjugated verb goes to the end.
+> I stay because it rains.;Ich bleibe, weil es regnet.
+? Ich bleibe, weil es ____. (regnen);regnet
+`
+	topic, err := parsing.ParseTopic(strings.NewReader(content), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing the grammar must not fail. Received: %v", err)
+	}
+	var out bytes.Buffer
+	i := newInterpreter(topic, getGenericInterrogationParameters(), &out)
+	i.params.SetLimit(1)
+	i.params.SetOutputStream(&out)
+	if err := i.execute("show grammar 1"); err != nil {
+		t.Fatalf("show grammar must not fail. Received: %v", err)
+	}
+	for _, expected := range []string{"Lesson 01 - Word order after weil", "the conjugated verb goes to the end", "Ich bleibe, weil es regnet. (I stay because it rains.)", "Ich bleibe, weil es ____. (regnen)", "regnet", "Session is over..."} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("show grammar must display %q. Output:\n%s", expected, out.String())
+		}
+	}
+	if err := i.execute("show colours"); err == nil {
+		t.Errorf("show must only accept grammar")
+	}
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// WriteLessons renders a table of the lessons of the topic with their
+// title and the number of words, sentences, grammar rules and conjugation
+// tables they contain.
+func WriteLessons(out io.Writer, topic datamodel.Topic) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Lesson\tTitle\tWords\tSentences\tGrammar\tConjugations\n")
+	for _, ID := range topic.GetAllSubsectionsName() {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\n", ID, formatTitle(topic.GetLessonTitle(ID)),
+			topic.GetVocabularySubsection(ID).GetCount(), countSentences(topic, ID),
+			len(topic.GetGrammar(ID)), len(topic.GetConjugations(ID)))
+	}
+	return w.Flush()
+}
+
+// WriteSentences renders the sentences of the lessons with their
+// translation. It returns the number of sentences written.
+func WriteSentences(out io.Writer, topic datamodel.Topic, lessonIDs []string) int {
+	count := 0
+	for _, ID := range lessonIDs {
+		if countSentences(topic, ID) == 0 {
+			continue
+		}
+		sentences := topic.GetSentencesSubsection(ID)
+		fmt.Fprintf(out, "Lesson %s", ID)
+		if title := formatTitle(topic.GetLessonTitle(ID)); title != "" {
+			fmt.Fprintf(out, " - %s", title)
+		}
+		fmt.Fprintln(out)
+		for j := 0; j < sentences.GetCount(); j++ {
+			fmt.Fprintf(out, "  * %s (%s)\n", sentences.GetAnswer(j), sentences.GetQuestion(j))
+		}
+		count += sentences.GetCount()
+	}
+	return count
+}
+
+// formatTitle returns the title of a lesson in the learnt language
+// followed by its translation.
+func formatTitle(title datamodel.Resource) string {
+	switch {
+	case title.Learning == "":
+		return title.Native
+	case title.Native == "":
+		return title.Learning
+	}
+	return fmt.Sprintf("%s (%s)", title.Learning, title.Native)
+}
+
+// countSentences returns the number of sentences of a lesson without
+// creating an empty subsection.
+func countSentences(topic datamodel.Topic, ID string) int {
+	for _, s := range topic.GetSentencesSubsectionsName() {
+		if s == ID {
+			return topic.GetSentencesSubsection(ID).GetCount()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,27 @@
+package engine
+
+import (
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+)
+
+// play runs the player command configured to play the audio clip passed in
+// parameter. Nothing is done if there is no clip or no player configured.
+func play(s datamodel.PlayerSettings, m *datamodel.Media, baseDir string) error {
+	if m == nil || !s.IsEnabled() {
+		return nil
+	}
+	return runHook(buildHookCommand(s.Command, map[string]string{
+		datamodel.MediaFilePlaceholder:  m.Resolve(baseDir),
+		datamodel.MediaStartPlaceholder: datamodel.FormatOffset(m.Start),
+		datamodel.MediaEndPlaceholder:   datamodel.FormatOffset(m.End),
+	}))
+}
+
+// playOrWarn plays the clip and only warns the user if it fails: the
+// session goes on even if the player is broken.
+func playOrWarn(s datamodel.PlayerSettings, m *datamodel.Media, baseDir string) {
+	if err := play(s, m, baseDir); err != nil {
+		tools.Warning(err.Error())
+	}
+}
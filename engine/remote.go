@@ -0,0 +1,297 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/pkg/errors"
+)
+
+// Acknowledgements written back on the control socket. An error is
+// followed by its description.
+const (
+	ackOK    = "ok"
+	ackError = "error"
+)
+
+// remoteControl listens on a Unix socket for the commands that drive a
+// session. Each line received is a command sent to the engine, which
+// answers with an acknowledgement written back on the same connection.
+// The commands of several connections are handled one at a time.
+type remoteControl struct {
+	listener net.Listener
+	path     string
+	commands chan<- string
+	replies  <-chan string
+	done     chan struct{}
+	// serializes the commands so each reply goes to the right connection
+	sync.Mutex
+}
+
+// listenForCommands creates the socket at path. The commands received are
+// sent to the commands channel and the replies read from the replies
+// channel are written back to the sender. A socket left by a session that
+// was killed is replaced.
+func listenForCommands(path string, commands chan<- string, replies <-chan string) (*remoteControl, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("the control socket %q is already used by another session", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, errors.Wrapf(err, "failed to remove the stale control socket %q", path)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the control socket %q", path)
+	}
+	// only the user running the session can drive it
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, errors.Wrapf(err, "failed to restrict the access to the control socket %q", path)
+	}
+	r := &remoteControl{
+		listener: listener,
+		path:     path,
+		commands: commands,
+		replies:  replies,
+		done:     make(chan struct{}),
+	}
+	go r.accept()
+	return r, nil
+}
+
+// accept handles the connections until the socket is closed.
+func (r *remoteControl) accept() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			select {
+			case <-r.done:
+			default:
+				tools.Warning(fmt.Sprintf("the control socket stopped accepting commands: %v", err))
+			}
+			return
+		}
+		go r.serve(conn)
+	}
+}
+
+// serve reads the commands of a connection, one per line, and writes an
+// acknowledgement line for each of them.
+func (r *remoteControl) serve(conn net.Conn) {
+	defer conn.Close()
+	s := bufio.NewScanner(conn)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		if _, err := fmt.Fprintln(conn, r.send(line)); err != nil {
+			return
+		}
+	}
+}
+
+// send gives the command to the engine and waits for its reply.
+func (r *remoteControl) send(line string) string {
+	r.Lock()
+	defer r.Unlock()
+	select {
+	case r.commands <- line:
+	case <-r.done:
+		return ackError + " the session is over"
+	}
+	// the engine replies to each command it reads
+	return <-r.replies
+}
+
+// Close stops listening and removes the socket.
+func (r *remoteControl) Close() {
+	close(r.done)
+	r.listener.Close()
+	os.Remove(r.path)
+}
+
+// userAction is what the user asks for the current question.
+type userAction int
+
+const (
+	// revealAction shows the answer then moves on
+	revealAction userAction = iota
+	// answerAction grades the attempt typed by the user
+	answerAction
+	// gradeAction records the self-assessment of the user
+	gradeAction
+	// quitAction stops the session and saves it
+	quitAction
+)
+
+// request is an action of the user with its argument.
+type request struct {
+	action  userAction
+	attempt string
+	grade   Grade
+	// remote is true if the request comes from the control socket, which
+	// waits for an acknowledgement
+	remote bool
+}
+
+// parseRemoteCommand reads a command received on the control socket.
+func parseRemoteCommand(line string) (request, error) {
+	name, args := splitCommand(line)
+	switch {
+	case (name == "next" || name == "reveal") && args == "":
+		return request{action: revealAction, remote: true}, nil
+	case name == "quit" && args == "":
+		return request{action: quitAction, remote: true}, nil
+	case name == "grade":
+		g, err := strconv.Atoi(args)
+		if err != nil || Grade(g) < GradeAgain || Grade(g) > GradeEasy {
+			return request{}, fmt.Errorf("the grade must be between %d and %d. Received %q", GradeAgain, GradeEasy, args)
+		}
+		return request{action: gradeAction, grade: Grade(g), remote: true}, nil
+	default:
+		return request{}, fmt.Errorf("%q is not a command. Commands are next, reveal, grade <1-4> and quit", line)
+	}
+}
+
+// parseTypedLine reads the line typed by the user for the current
+// question: an empty line is a request to see the answer, :quit stops the
+// session and any other line is an attempt.
+func parseTypedLine(line string) request {
+	switch line {
+	case "":
+		return request{action: revealAction}
+	case quitCommand:
+		return request{action: quitAction}
+	default:
+		return request{action: answerAction, attempt: line}
+	}
+}
+
+// userInput gives the requests of the user for the questions: the lines
+// typed in the terminal in interactive mode and the commands received on
+// the control socket.
+type userInput struct {
+	p       datamodel.InterrogationParameters
+	scanner *bufio.Scanner
+	// lines typed in the terminal, read in the background so the commands
+	// of the control socket can be received meanwhile. It is nil if there
+	// is no control socket: the terminal is then read directly.
+	lines <-chan string
+	// asks the background reader for the next line. A line is only read
+	// when a question waits for it so the terminal is left alone once the
+	// session is over.
+	nextLine chan<- struct{}
+	reading  bool
+	// closed at the end of the session to stop the background reader
+	done     chan struct{}
+	commands <-chan string
+	replies  chan<- string
+}
+
+// newUserInput prepares the reading of the requests of the user.
+func newUserInput(p datamodel.InterrogationParameters) *userInput {
+	u := &userInput{
+		p:        p,
+		scanner:  bufio.NewScanner(p.GetInputStream()),
+		commands: p.Command,
+		replies:  p.Replies,
+	}
+	if p.IsInteractive() && p.GetControlSocket() != "" {
+		lines := make(chan string)
+		nextLine := make(chan struct{}, 1)
+		u.done = make(chan struct{})
+		go func() {
+			defer close(lines)
+			for {
+				select {
+				case <-nextLine:
+				case <-u.done:
+					return
+				}
+				if !u.scanner.Scan() {
+					return
+				}
+				select {
+				case lines <- u.scanner.Text():
+				case <-u.done:
+					// the session is over: nobody waits for the line
+					return
+				}
+			}
+		}()
+		u.lines, u.nextLine = lines, nextLine
+	}
+	return u
+}
+
+// close stops reading the terminal in the background. A line already
+// asked for is dropped when it is typed.
+func (u *userInput) close() {
+	if u.done != nil {
+		close(u.done)
+	}
+}
+
+// wait returns the request of the user for the card. In interactive mode,
+// it waits for a line typed in the terminal. Otherwise the answer is
+// revealed once the pause is over. In both cases, a command received on
+// the control socket cuts the wait short. An invalid command is answered
+// with an error and the wait goes on.
+func (u *userInput) wait(card Card) request {
+	var pauseOver <-chan time.Time
+	if !u.p.IsInteractive() {
+		pauseOver = time.After(u.p.GetPauseFor(card.Kind, card.Question))
+	} else if u.lines == nil {
+		if u.scanner.Scan() {
+			return parseTypedLine(u.scanner.Text())
+		}
+		return request{action: revealAction}
+	} else if !u.reading {
+		u.nextLine <- struct{}{}
+		u.reading = true
+	}
+	for {
+		select {
+		case <-pauseOver:
+			return request{action: revealAction}
+		case line, ok := <-u.lines:
+			if !ok {
+				// nothing more can be typed
+				return request{action: revealAction}
+			}
+			u.reading = false
+			return parseTypedLine(line)
+		case line := <-u.commands:
+			r, err := parseRemoteCommand(line)
+			if err != nil {
+				u.acknowledge(request{remote: true}, err)
+				continue
+			}
+			return r
+		}
+	}
+}
+
+// acknowledge replies to the control socket once its request is done.
+func (u *userInput) acknowledge(r request, err error) {
+	if !r.remote {
+		return
+	}
+	if err != nil {
+		u.replies <- fmt.Sprintf("%s %v", ackError, err)
+		return
+	}
+	u.replies <- ackOK
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/pkg/errors"
+)
+
+const (
+	// scriptComment starts a comment line in a script
+	scriptComment = "#"
+	// scriptCommandsSep separates the commands written on the same line
+	scriptCommandsSep = ";"
+)
+
+// variableName is the syntax of the names of the variables.
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// variableReference matches $name and ${name} in a command.
+var variableReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// RunScript runs the interpreter commands read from r, in sequence, with
+// no prompt. Each line holds one or more commands separated by ";" and the
+// lines starting with "#" are comments. The script stops at the first
+// command that fails: the error tells the line of the command. A quit
+// command stops the script with no error.
+func RunScript(t datamodel.Topic, p datamodel.InterrogationParameters, r io.Reader, name string) error {
+	i := newInterpreter(t, p, p.GetOutputStream())
+	err := i.runScript(r, name)
+	if err == errQuit {
+		return nil
+	}
+	return err
+}
+
+// runScriptFile runs the commands of the script file.
+func (i *interpreter) runScriptFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the script %q", path)
+	}
+	defer f.Close()
+	return i.runScript(f, path)
+}
+
+// runScript runs the commands read from r. name is used in the errors to
+// locate the command that failed. errQuit is returned if the script quits.
+func (i *interpreter) runScript(r io.Reader, name string) error {
+	s := bufio.NewScanner(r)
+	lineNumber := 0
+	for s.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, scriptComment) {
+			continue
+		}
+		for _, c := range strings.Split(line, scriptCommandsSep) {
+			c = strings.TrimSpace(c)
+			if c == "" {
+				continue
+			}
+			fmt.Fprintf(i.out, "> %s\n", c)
+			err := i.execute(c)
+			if err == errQuit {
+				return err
+			}
+			if err != nil {
+				return errors.Wrapf(err, "%s:%d", name, lineNumber)
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return errors.Wrapf(err, "failed to read the script %q", name)
+	}
+	return nil
+}
+
+// expandVariables replaces the references to the variables by their value.
+func (i *interpreter) expandVariables(userInput string) (string, error) {
+	var undefined []string
+	expanded := variableReference.ReplaceAllStringFunc(userInput, func(ref string) string {
+		m := variableReference.FindStringSubmatch(ref)
+		name := m[1] + m[2]
+		value, ok := i.variables[name]
+		if !ok {
+			undefined = append(undefined, "$"+name)
+			return ref
+		}
+		return value
+	})
+	if len(undefined) != 0 {
+		return userInput, fmt.Errorf("undefined variable(s): %s", strings.Join(undefined, ", "))
+	}
+	return expanded, nil
+}
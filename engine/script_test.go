@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/internal/tests"
+	"github.com/boris-lenzinger/repeatit/parsing"
+)
+
+func TestRunScript(t *testing.T) {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	testCases := []struct {
+		script   string
+		sessions int
+		err      string
+	}{
+		{"# routine\nlet first = 1\nset limit 1; select $first\n\nselect ${first}\n", 2, ""},
+		{"set limit 1\nselect 1; quit\nselect 1\n", 1, ""},
+		{"set limit 1\nselect 1\nset reverse maybe\nselect 1\n", 1, "routine.rit:3"},
+		{"select $undefined\n", 0, "routine.rit:1: undefined variable(s): $undefined"},
+		{"let 1st = 1\n", 0, "routine.rit:1"},
+	}
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		p := getGenericInterrogationParameters()
+		p.SetOutputStream(&out)
+		err := RunScript(topic, p, strings.NewReader(tc.script), "routine.rit")
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("Script %q must not fail. Received: %v", tc.script, err)
+		case tc.err != "" && (err == nil || !strings.HasPrefix(err.Error(), tc.err)):
+			t.Errorf("Script %q must fail with %q. Received: %v", tc.script, tc.err, err)
+		}
+		if count := strings.Count(out.String(), "Session is over..."); count != tc.sessions {
+			t.Errorf("Script %q: expected %d sessions but got %d. Output:\n%s", tc.script, tc.sessions, count, out.String())
+		}
+	}
+}
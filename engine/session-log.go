@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/pkg/errors"
+)
+
+// logSession appends a line describing the session to the sessions log of
+// the data directory. The line contains everything needed to replay the
+// session in the same order: lessons file, selection, mode and seed.
+// Nothing is logged if no data directory is set.
+func logSession(p datamodel.InterrogationParameters, nbOfQuestions int) error {
+	path := p.GetSessionsLogFile()
+	if path == "" {
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(path), tools.DefaultPermission)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the data directory %q", filepath.Dir(path))
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the sessions log %q", path)
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s file=%q selection=%q mode=%s reversed=%t seed=%d questions=%d loops=%d\n",
+		time.Now().Format(time.RFC3339), p.GetLessonsFile(), strings.Join(p.GetListOfSubsections(), ","),
+		modeName(p), p.IsReversedMode(), p.GetSeed(), nbOfQuestions, p.GetLimit())
+	if err != nil {
+		return errors.Wrapf(err, "failed to write to the sessions log %q", path)
+	}
+	return nil
+}
+
+// modeName returns the name of the interrogation mode for the logs.
+func modeName(p datamodel.InterrogationParameters) string {
+	switch {
+	case p.IsWeightedMode():
+		return "weighted"
+	case p.IsRandomMode():
+		return "random"
+	default:
+		return "linear"
+	}
+}
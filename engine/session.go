@@ -0,0 +1,435 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+	"github.com/spf13/viper"
+)
+
+// EventKind tells what happened in a session.
+type EventKind int
+
+const (
+	// LoopStarted is sent when a new loop on the questions starts
+	LoopStarted EventKind = iota
+	// QuestionAsked is sent when a new question is drawn
+	QuestionAsked
+	// AnswerGraded is sent when the attempt of the user is graded
+	AnswerGraded
+	// AnswerRevealed is sent when the answer is shown to the user
+	AnswerRevealed
+	// SessionEnded is sent once, when the session is over or closed
+	SessionEnded
+)
+
+// String returns the name of the kind of event.
+func (k EventKind) String() string {
+	switch k {
+	case LoopStarted:
+		return "loop-started"
+	case QuestionAsked:
+		return "question-asked"
+	case AnswerGraded:
+		return "answer-graded"
+	case AnswerRevealed:
+		return "answer-revealed"
+	default:
+		return "session-ended"
+	}
+}
+
+// Event describes what happened in a session. Only the fields that make
+// sense for the kind of event are set.
+type Event struct {
+	Kind EventKind
+	// Loop is the number of the current loop, starting at 1
+	Loop int
+	// Limit is the number of loops of the session
+	Limit int
+	// Card is the question concerned by the event
+	Card Card
+	// Answer is set when the answer is revealed
+	Answer string
+	// Attempt and Correct are set when an answer is graded
+	Attempt string
+	Correct bool
+	// Reason is set when the session ends before the end of the loops
+	Reason string
+}
+
+// Card is a question asked to the user.
+type Card struct {
+	// Index of the question in the questions set
+	Index int
+	// Question is the text displayed to the user
+	Question string
+	// Direction tells if the question is in the native or the learnt
+	// language
+	Direction datamodel.Direction
+	// Kind tells if the question is a word or a sentence
+	Kind datamodel.EntryKind
+}
+
+// DirectionResult counts the graded answers in a direction.
+type DirectionResult struct {
+	Correct int
+	Asked   int
+}
+
+// Session drives the questioning of a user on a set of questions. It does
+// no input/output: the caller displays the cards returned by Next, sends
+// the attempts of the user with Answer and shows the answer returned by
+// Reveal. What happens is also sent to the event callback.
+// A session is not safe for concurrent use.
+type Session struct {
+	qa      datamodel.QuestionsAnswers
+	p       datamodel.InterrogationParameters
+	onEvent func(Event)
+
+	history     datamodel.History
+	historyFile string
+	progress    *sessionProgress
+	results     directionResults
+	resumed     *datamodel.Snapshot
+
+	// position in the session
+	loop        int
+	asked       int
+	i           int
+	previous    int
+	askedInLoop map[int]int
+	covered     map[int]bool
+	startedAt   time.Time
+
+	// the card being asked
+	current    Card
+	hasCurrent bool
+	askedAt    time.Time
+	graded     bool
+
+	over           bool
+	stoppedBecause string
+}
+
+// NewSession creates a session on the lessons of the topic. The parameters
+// tell which exercise is built, for which lessons (see SetExercise and
+// SetListOfSubsections), in which order the questions are drawn and when
+// the session stops. Only the entries matching the tag filter are asked.
+// onEvent is called for each event of the session and can be nil.
+func NewSession(t datamodel.Topic, p datamodel.InterrogationParameters, onEvent func(Event)) (*Session, error) {
+	qa := t.BuildExerciseQuestionsSet(p.GetExercise(), p.GetRandom(), p.GetListOfSubsections()...).Filter(p.GetTagFilter()).FilterOnDifficulty(p.GetDifficultyRange())
+	return newSession(qa, p, onEvent)
+}
+
+// newSession creates a session on a set of questions.
+func newSession(qa datamodel.QuestionsAnswers, p datamodel.InterrogationParameters, onEvent func(Event)) (*Session, error) {
+	if qa.GetCount() == 0 {
+		return nil, fmt.Errorf("Number of questions is zero. Please check your file")
+	}
+	s := &Session{
+		qa:          qa,
+		p:           p,
+		onEvent:     onEvent,
+		history:     datamodel.NewHistory(),
+		historyFile: p.GetHistoryFile(),
+		results:     newDirectionResults(),
+		resumed:     p.GetResumedSnapshot(),
+		previous:    -1,
+		covered:     make(map[int]bool),
+		startedAt:   time.Now(),
+	}
+	if s.historyFile != "" {
+		var err error
+		s.history, err = datamodel.LoadHistory(s.historyFile)
+		if err != nil && p.IsWeightedMode() {
+			// the weights cannot be computed without the results
+			return nil, err
+		} else if err != nil {
+			// the history is not saved either so the file can be fixed
+			// without losing the results it holds
+			tools.Warning(fmt.Sprintf("the results of the session will not be recorded: %v", err))
+			s.history, s.historyFile = datamodel.NewHistory(), ""
+		}
+	}
+	if s.resumed != nil {
+		var err error
+		s.askedInLoop, err = restoreProgress(s.resumed, qa.GetCount())
+		if err != nil {
+			return nil, err
+		}
+		s.loop, s.asked, s.i = s.resumed.Loop, s.resumed.QuestionsAsked, s.resumed.Next
+		s.startedAt = s.startedAt.Add(-s.resumed.Elapsed)
+	}
+	s.progress = newSessionProgress(p, &s.history, s.startedAt)
+	s.progress.update(s.loop, s.asked, s.i, s.askedInLoop)
+	return s, nil
+}
+
+// Next draws the next question. It returns false once the session is over:
+// the number of loops is reached or a stop criterion is met. Calling Next
+// before Reveal skips the current question.
+func (s *Session) Next() (Card, bool) {
+	if s.over {
+		return Card{}, false
+	}
+	if s.hasCurrent {
+		s.moveOn()
+	}
+	n := s.qa.GetCount()
+	if s.asked%n == 0 {
+		s.askedInLoop = make(map[int]int)
+		s.loop++
+		if s.loop > s.p.GetLimit() {
+			s.end("")
+			return Card{}, false
+		}
+		s.emit(Event{Kind: LoopStarted})
+	}
+	if reason := checkStopCriteria(s.p, s.startedAt, s.asked); reason != "" {
+		s.end(reason)
+		return Card{}, false
+	}
+	if s.p.IsRandomMode() {
+		var present bool
+		for {
+			s.i = int(s.p.GetRandom().Int31n(int32(n)))
+			if _, present = s.askedInLoop[s.i]; !present {
+				break
+			}
+			if present && !viper.GetBool("avoidRepetition") {
+				break
+			}
+			// we need a new randon number...
+		}
+	}
+	direction := s.p.PickDirection()
+	if datamodel.IsOneWayExercise(s.p.GetExercise()) {
+		direction = datamodel.Production
+	}
+	if s.p.IsWeightedMode() {
+		// Repetitions are expected in weighted mode: this is how the
+		// difficult items come back more often.
+		s.i = drawWeighted(s.p.GetRandom(), s.qa, s.history, s.previous, direction)
+	}
+	if s.qa.GetScrambledWords(s.i) != nil {
+		// the shuffled words can only be put back in order
+		direction = datamodel.Production
+	}
+	s.askedInLoop[s.i] = s.i
+	s.covered[s.i] = true
+	question := s.qa.GetQuestion(s.i)
+	if direction == datamodel.Recognition {
+		// user has requested Jeopardy like
+		question = s.qa.GetAnswer(s.i)
+	}
+	s.current = Card{
+		Index:     s.i,
+		Question:  question,
+		Direction: direction,
+		Kind:      s.qa.GetKind(s.i),
+	}
+	s.hasCurrent, s.graded = true, false
+	s.askedAt = time.Now()
+	s.emit(Event{Kind: QuestionAsked, Card: s.current})
+	return s.current, true
+}
+
+// Answer grades the attempt of the user for the current question and
+// records the result. An empty attempt is not graded. A question can only
+// be graded once.
+func (s *Session) Answer(attempt string) (bool, error) {
+	if !s.hasCurrent {
+		return false, fmt.Errorf("there is no question to answer")
+	}
+	if attempt == "" {
+		return false, nil
+	}
+	if s.graded {
+		return false, fmt.Errorf("the question %q has already been answered", s.current.Question)
+	}
+	correct := GradeAttempt(s.qa, s.i, s.current.Direction, attempt)
+	s.progress.record(s.qa.GetDirectionalKey(s.i, s.current.Direction), correct, time.Since(s.askedAt))
+	s.results.record(s.current.Direction, correct)
+	s.graded = true
+	s.emit(Event{Kind: AnswerGraded, Card: s.current, Attempt: attempt, Correct: correct})
+	return correct, nil
+}
+
+// Grade records the self-assessment of the user for the current question,
+// when the answer was not typed. GradeAgain counts as a wrong answer, the
+// other grades as a correct one.
+func (s *Session) Grade(g Grade) error {
+	if g < GradeAgain || g > GradeEasy {
+		return fmt.Errorf("the grade must be between %d and %d. Received %d", GradeAgain, GradeEasy, g)
+	}
+	if !s.hasCurrent {
+		return fmt.Errorf("there is no question to grade")
+	}
+	if s.graded {
+		return fmt.Errorf("the question %q has already been answered", s.current.Question)
+	}
+	correct := g != GradeAgain
+	s.progress.record(s.qa.GetDirectionalKey(s.i, s.current.Direction), correct, time.Since(s.askedAt))
+	s.results.record(s.current.Direction, correct)
+	s.graded = true
+	s.emit(Event{Kind: AnswerGraded, Card: s.current, Attempt: g.String(), Correct: correct})
+	return nil
+}
+
+// Hint returns the answer of the current question with only the first
+// letters of each word visible. The higher the level, the more letters are
+// visible.
+func (s *Session) Hint(level int) (string, error) {
+	if !s.hasCurrent {
+		return "", fmt.Errorf("there is no question to give a hint for")
+	}
+	answer := s.qa.GetAnswer(s.i)
+	if s.current.Direction == datamodel.Recognition {
+		answer = s.qa.GetQuestion(s.i)
+	}
+	return maskAnswer(answer, level), nil
+}
+
+// ToggleFlag marks the current question to review it later, or removes the
+// mark. The mark is kept in the history. It returns the new state.
+func (s *Session) ToggleFlag() (bool, error) {
+	if !s.hasCurrent {
+		return false, fmt.Errorf("there is no question to flag")
+	}
+	return s.progress.toggleFlag(s.qa.GetKey(s.i)), nil
+}
+
+// IsFlagged tells if the current question is marked to be reviewed.
+func (s *Session) IsFlagged() bool {
+	return s.hasCurrent && s.history.IsFlagged(s.qa.GetKey(s.i))
+}
+
+// GetRecord returns what the history knows about the current question in
+// its direction. It returns nil if the question was never graded.
+func (s *Session) GetRecord() *datamodel.ItemRecord {
+	if !s.hasCurrent {
+		return nil
+	}
+	return s.history.Items[s.qa.GetDirectionalKey(s.i, s.current.Direction)]
+}
+
+// GetQuestionsAskedInLoop returns the number of questions already asked in
+// the current loop, the current one excluded.
+func (s *Session) GetQuestionsAskedInLoop() int {
+	return s.asked % s.qa.GetCount()
+}
+
+// GetSubsections returns the lessons of the session.
+func (s *Session) GetSubsections() []string {
+	return s.p.GetListOfSubsections()
+}
+
+// Reveal returns the answer of the current question.
+func (s *Session) Reveal() (string, error) {
+	if !s.hasCurrent {
+		return "", fmt.Errorf("there is no question to reveal")
+	}
+	answer := s.qa.GetAnswer(s.i)
+	if s.current.Direction == datamodel.Recognition {
+		answer = s.qa.GetQuestion(s.i)
+	}
+	s.emit(Event{Kind: AnswerRevealed, Card: s.current, Answer: answer})
+	return answer, nil
+}
+
+// Close ends the session and saves the results in the history. A session
+// that is not over is dropped: see Suspend to save it instead.
+func (s *Session) Close() error {
+	if !s.over {
+		s.over = true
+		s.emit(Event{Kind: SessionEnded, Reason: "closed before the end"})
+	} else if s.resumed != nil {
+		// the resumed session is over: there is nothing left to resume
+		if err := datamodel.RemoveSnapshot(s.p.GetSnapshotFile()); err != nil {
+			return err
+		}
+	}
+	if s.historyFile != "" {
+		return s.progress.saveHistory(s.historyFile)
+	}
+	return nil
+}
+
+// Suspend is the same as Close but a session that is not over is saved so
+// it can be resumed later with the resume command. There is only one saved
+// session: it replaces the one saved before.
+func (s *Session) Suspend() error {
+	if !s.over && s.progress.canBeSaved() {
+		if err := s.progress.save(); err != nil {
+			return err
+		}
+	}
+	return s.Close()
+}
+
+// IsOver tells if all the questions of the session have been asked.
+func (s *Session) IsOver() bool {
+	return s.over
+}
+
+// GetQuestionsCount returns the number of questions of the session.
+func (s *Session) GetQuestionsCount() int {
+	return s.qa.GetCount()
+}
+
+// GetLoop returns the number of the current loop, starting at 1.
+func (s *Session) GetLoop() int {
+	return s.loop
+}
+
+// GetLimit returns the number of loops of the session.
+func (s *Session) GetLimit() int {
+	return s.p.GetLimit()
+}
+
+// GetQuestionsAsked returns the number of questions asked since the start
+// of the session, the current one excluded.
+func (s *Session) GetQuestionsAsked() int {
+	return s.asked
+}
+
+// GetResults returns the results of the graded answers for each direction.
+func (s *Session) GetResults() map[datamodel.Direction]DirectionResult {
+	results := make(map[datamodel.Direction]DirectionResult)
+	for d, asked := range s.results.asked {
+		results[d] = DirectionResult{Correct: s.results.correct[d], Asked: asked}
+	}
+	return results
+}
+
+// moveOn records that the current question is done.
+func (s *Session) moveOn() {
+	s.previous = s.i
+	if !s.p.IsRandomMode() && !s.p.IsWeightedMode() {
+		s.i = (s.i + 1) % s.qa.GetCount()
+	}
+	s.asked++
+	s.hasCurrent = false
+	s.progress.update(s.loop, s.asked, s.i, s.askedInLoop)
+}
+
+// end marks the session as over and tells why if it stopped before the end
+// of the loops.
+func (s *Session) end(reason string) {
+	s.over = true
+	s.stoppedBecause = reason
+	s.emit(Event{Kind: SessionEnded, Reason: reason})
+}
+
+// emit sends an event to the callback of the session.
+func (s *Session) emit(e Event) {
+	if s.onEvent == nil {
+		return
+	}
+	e.Loop = s.loop
+	e.Limit = s.p.GetLimit()
+	s.onEvent(e)
+}
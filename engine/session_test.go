@@ -0,0 +1,259 @@
+package engine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/internal/tests"
+	"github.com/boris-lenzinger/repeatit/parsing"
+)
+
+func TestSession(t *testing.T) {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	qa := topic.BuildVocabularyQuestionsSet("03")
+
+	p := getGenericInterrogationParameters()
+	p.SetLimit(2)
+	p.SetDataDir(t.TempDir())
+	p.SetExercise(datamodel.ExerciseVocabulary)
+	p.SetListOfSubsections("03")
+	events := map[EventKind]int{}
+	session, err := NewSession(topic, p, func(e Event) {
+		events[e.Kind]++
+	})
+	if err != nil {
+		t.Fatalf("creating a session must not fail. Received: %v", err)
+	}
+	if _, err := session.Answer("anything"); err == nil {
+		t.Errorf("Answering before the first question must fail")
+	}
+
+	asked := 0
+	for {
+		card, ok := session.Next()
+		if !ok {
+			break
+		}
+		if card.Question != qa.GetQuestion(asked%qa.GetCount()) {
+			t.Errorf("Expected question %q in linear mode but got %q", qa.GetQuestion(asked%qa.GetCount()), card.Question)
+		}
+		attempt := qa.GetAnswer(card.Index)
+		if asked%2 == 1 {
+			attempt = "wrong"
+		}
+		correct, err := session.Answer(attempt)
+		if err != nil || correct != (asked%2 == 0) {
+			t.Errorf("Attempt %q for %q: expected correct=%t but got %t (err: %v)", attempt, card.Question, asked%2 == 0, correct, err)
+		}
+		if _, err := session.Answer(attempt); err == nil {
+			t.Errorf("A question must not be graded twice")
+		}
+		answer, err := session.Reveal()
+		if err != nil || answer != qa.GetAnswer(card.Index) {
+			t.Errorf("Expected answer %q but got %q (err: %v)", qa.GetAnswer(card.Index), answer, err)
+		}
+		asked++
+	}
+	if asked != 2*qa.GetCount() || !session.IsOver() {
+		t.Errorf("Expected %d questions in 2 loops but got %d", 2*qa.GetCount(), asked)
+	}
+	if events[LoopStarted] != 2 || events[QuestionAsked] != asked || events[AnswerGraded] != asked ||
+		events[AnswerRevealed] != asked || events[SessionEnded] != 1 {
+		t.Errorf("Unexpected events: %v", events)
+	}
+	if r := session.GetResults()[datamodel.Production]; r.Asked != asked || r.Correct != asked/2 {
+		t.Errorf("Expected %d correct answers out of %d but got %+v", asked/2, asked, r)
+	}
+	if err := session.Close(); err != nil {
+		t.Fatalf("closing the session must not fail. Received: %v", err)
+	}
+	history, err := datamodel.LoadHistory(p.GetHistoryFile())
+	if err != nil || len(history.Items) != qa.GetCount() {
+		t.Errorf("The results must be saved in the history. Got %d items (err: %v)", len(history.Items), err)
+	}
+}
+
+func TestBroadcasterDoesNotBlock(t *testing.T) {
+	b := NewBroadcaster()
+	slow, _ := b.Subscribe(1)
+	fast, unsubscribe := b.Subscribe(3)
+	for i := 1; i <= 3; i++ {
+		b.Publish(Event{Kind: QuestionAsked, Loop: i})
+	}
+	if e := <-slow; e.Loop != 1 {
+		t.Errorf("The slow subscriber must keep the first event. Got loop %d", e.Loop)
+	}
+	for i := 1; i <= 3; i++ {
+		if e := <-fast; e.Loop != i {
+			t.Errorf("Expected event %d but got %d", i, e.Loop)
+		}
+	}
+	unsubscribe()
+	if _, ok := <-fast; ok {
+		t.Errorf("The channel must be closed once unsubscribed")
+	}
+	b.Close()
+	if _, ok := <-slow; ok {
+		t.Errorf("The channels must be closed with the broadcaster")
+	}
+	if late, _ := b.Subscribe(1); late != nil {
+		if _, ok := <-late; ok {
+			t.Errorf("Subscribing to a closed broadcaster must return a closed channel")
+		}
+	}
+}
+
+// TestScrambleIsAskedInProduction checks that the shuffled words of a
+// sentence are asked in production even if the session is reversed, so the
+// user can put them back in order.
+func TestScrambleIsAskedInProduction(t *testing.T) {
+	sentences := datamodel.NewQA()
+	sentences.AddEntryOfKind("The cat sleeps.", "Le chat dort.", datamodel.Sentence)
+	topic := datamodel.NewTopic()
+	topic.SetSentencesSubsection("01", sentences)
+
+	p := getGenericInterrogationParameters()
+	p.SetLimit(1)
+	p.SetSeed(1)
+	p.SetReverseMode()
+	p.SetExercise(datamodel.ExerciseScramble)
+	p.SetListOfSubsections("01")
+	session, err := NewSession(topic, p, nil)
+	if err != nil {
+		t.Fatalf("creating a session must not fail. Received: %v", err)
+	}
+	card, ok := session.Next()
+	if !ok {
+		t.Fatalf("The session must ask the scrambled sentence")
+	}
+	if card.Direction != datamodel.Production || !strings.HasSuffix(card.Question, "(The cat sleeps.)") {
+		t.Errorf("Expected the shuffled words in production but got %q in %s", card.Question, card.Direction)
+	}
+	if correct, err := session.Answer("le chat dort."); err != nil || !correct {
+		t.Errorf("The sentence put back in order must be correct (err: %v)", err)
+	}
+}
+
+// TestDerivedExercisesAreAskedInProduction checks that the exercises built
+// from the lessons are asked in production even if the session is
+// reversed: their prompt is not an answer the user could find back.
+func TestDerivedExercisesAreAskedInProduction(t *testing.T) {
+	vocabulary := datamodel.NewQA()
+	vocabulary.AddEntry("house", "Haus")
+	vocabulary.SetWordInfo(0, &datamodel.WordInfo{Gender: datamodel.Neuter})
+	sentences := datamodel.NewQA()
+	sentences.AddEntryOfKind("The house is big.", "Das Haus ist groß.", datamodel.Sentence)
+	topic := datamodel.NewTopic()
+	topic.LearnedLanguage = "German"
+	topic.SetVocabularySubsection("01", vocabulary)
+	topic.SetSentencesSubsection("01", sentences)
+	topic.SetGrammar("01", []datamodel.GrammarRule{{
+		Title:     "Word order after weil",
+		Exercises: []datamodel.GrammarExercise{{Kind: datamodel.FillInExercise, Prompt: "Ich bleibe, weil es ___ (regnen).", Answer: "regnet"}},
+	}})
+	table, err := datamodel.NewConjugationTable("sein", "präsens", []string{"ich"}, []string{"bin"})
+	if err != nil {
+		t.Fatalf("creating the conjugation table must not fail. Received: %v", err)
+	}
+	topic.AddConjugation("01", table)
+
+	expected := map[string]struct{ question, answer string }{
+		datamodel.ExerciseForms:       {"gender of Haus?", "das"},
+		datamodel.ExerciseCloze:       {"Das ____ ist groß. (The house is big.)", "Haus"},
+		datamodel.ExerciseGrammar:     {"Ich bleibe, weil es ___ (regnen).", "regnet"},
+		datamodel.ExerciseConjugation: {"sein, präsens, ich?", "bin"},
+	}
+	for exercise, e := range expected {
+		p := getGenericInterrogationParameters()
+		p.SetLimit(1)
+		p.SetReverseMode()
+		p.SetExercise(exercise)
+		p.SetListOfSubsections("01")
+		session, err := NewSession(topic, p, nil)
+		if err != nil {
+			t.Fatalf("creating a %s session must not fail. Received: %v", exercise, err)
+		}
+		card, ok := session.Next()
+		if !ok {
+			t.Fatalf("The %s session must ask a question", exercise)
+		}
+		if card.Direction != datamodel.Production || card.Question != e.question {
+			t.Errorf("%s: expected %q in production but got %q in %s", exercise, e.question, card.Question, card.Direction)
+		}
+		if correct, err := session.Answer(e.answer); err != nil || !correct {
+			t.Errorf("%s: the answer %q must be correct (err: %v)", exercise, e.answer, err)
+		}
+	}
+}
+
+// TestSessionWithACorruptHistory checks that a history that cannot be read
+// only stops the weighted sessions, and that it is left as is.
+func TestSessionWithACorruptHistory(t *testing.T) {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	p := getGenericInterrogationParameters()
+	p.SetLimit(1)
+	p.SetDataDir(t.TempDir())
+	p.SetExercise(datamodel.ExerciseVocabulary)
+	p.SetListOfSubsections("03")
+	corrupt := []byte("{not json")
+	if err := ioutil.WriteFile(p.GetHistoryFile(), corrupt, 0644); err != nil {
+		t.Fatalf("failed to write the history: %v", err)
+	}
+
+	session, err := NewSession(topic, p, nil)
+	if err != nil {
+		t.Fatalf("a corrupt history must not stop the session. Received: %v", err)
+	}
+	for {
+		card, ok := session.Next()
+		if !ok {
+			break
+		}
+		session.Answer(card.Question)
+		session.Reveal()
+	}
+	if err := session.Close(); err != nil {
+		t.Fatalf("closing the session must not fail. Received: %v", err)
+	}
+	if content, err := ioutil.ReadFile(p.GetHistoryFile()); err != nil || !bytes.Equal(content, corrupt) {
+		t.Errorf("The corrupt history must be left as is. Got %q (err: %v)", content, err)
+	}
+
+	p.SetWeightedMode()
+	if _, err := NewSession(topic, p, nil); err == nil {
+		t.Errorf("A weighted session needs the history: a corrupt one must stop it")
+	}
+}
+
+// TestWeightedDrawFavorsTheHardestEntries checks that, with the same
+// history, the entry rated the hardest is drawn more often.
+func TestWeightedDrawFavorsTheHardestEntries(t *testing.T) {
+	qa := datamodel.NewQA()
+	qa.AddEntry("easy", "facile")
+	qa.SetDifficulty(0, datamodel.MinDifficulty)
+	qa.AddEntry("hard", "difficile")
+	qa.SetDifficulty(1, datamodel.MaxDifficulty)
+	h := datamodel.NewHistory()
+	rng := rand.New(rand.NewSource(1))
+	drawn := make([]int, 2)
+	for n := 0; n < 3000; n++ {
+		drawn[drawWeighted(rng, qa, h, -1, datamodel.Production)]++
+	}
+	// the hard entry weighs twice as much as the easy one
+	if drawn[1] < 3*drawn[0]/2 {
+		t.Errorf("Expected the hard entry to be drawn about twice as often as the easy one but got %v", drawn)
+	}
+	if difficultyFactor(0) != 1 {
+		t.Errorf("An entry without difficulty must keep its weight. Got a factor %.2f", difficultyFactor(0))
+	}
+}
@@ -0,0 +1,206 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+)
+
+// quitCommand is the answer to type in interactive mode to stop the session
+// and save it so it can be resumed later.
+const quitCommand = ":quit"
+
+// interruptedExitCode is the exit status when the session is interrupted
+// with Ctrl-C.
+const interruptedExitCode = 130
+
+// sessionProgress keeps track of the progress of a session so it can be
+// saved when the session is interrupted. It is shared with the goroutine
+// watching the interruptions, hence the lock.
+type sessionProgress struct {
+	sync.Mutex
+	snapshot  datamodel.Snapshot
+	history   *datamodel.History
+	startedAt time.Time
+	// path to the snapshot file. Empty if the session cannot be saved.
+	path string
+	// changes made to the history since it was saved
+	changes []historyChange
+}
+
+// historyChange is an answer or a flag recorded during the session. The
+// changes are replayed on the history file when it is saved so the results
+// saved by the other sessions in the meantime are kept.
+type historyChange struct {
+	key        string
+	correct    bool
+	elapsed    time.Duration
+	toggleFlag bool
+}
+
+// apply records the change in the history.
+func (c historyChange) apply(h *datamodel.History) {
+	if c.toggleFlag {
+		h.ToggleFlag(c.key)
+		return
+	}
+	h.Record(c.key, c.correct, c.elapsed)
+}
+
+// newSessionProgress prepares the tracking of the progress of the session.
+// If no snapshot file is available, the results are still recorded in the
+// history but the session cannot be saved.
+func newSessionProgress(p datamodel.InterrogationParameters, history *datamodel.History, startedAt time.Time) *sessionProgress {
+	progress := &sessionProgress{
+		history:   history,
+		startedAt: startedAt,
+		path:      p.GetSnapshotFile(),
+	}
+	if progress.path == "" {
+		return progress
+	}
+	snapshot, err := datamodel.NewSnapshot(p)
+	if err != nil {
+		tools.Warning(fmt.Sprintf("the session cannot be saved on interruption: %v", err))
+		progress.path = ""
+		return progress
+	}
+	if resumed := p.GetResumedSnapshot(); resumed != nil {
+		snapshot.Results = resumed.Results
+	}
+	progress.snapshot = snapshot
+	return progress
+}
+
+// canBeSaved tells if the session can be saved to be resumed later.
+func (progress *sessionProgress) canBeSaved() bool {
+	return progress.path != ""
+}
+
+// update records where the session is after a question.
+func (progress *sessionProgress) update(loop, questionsAsked, next int, askedInLoop map[int]int) {
+	progress.Lock()
+	defer progress.Unlock()
+	progress.snapshot.Loop = loop
+	progress.snapshot.QuestionsAsked = questionsAsked
+	progress.snapshot.Next = next
+	progress.snapshot.AskedInLoop = make([]int, 0, len(askedInLoop))
+	for i := range askedInLoop {
+		progress.snapshot.AskedInLoop = append(progress.snapshot.AskedInLoop, i)
+	}
+	sort.Ints(progress.snapshot.AskedInLoop)
+}
+
+// record stores the result of an answer in the history and in the results
+// of the session.
+func (progress *sessionProgress) record(key string, correct bool, elapsed time.Duration) {
+	progress.Lock()
+	defer progress.Unlock()
+	change := historyChange{key: key, correct: correct, elapsed: elapsed}
+	change.apply(progress.history)
+	progress.changes = append(progress.changes, change)
+	if progress.canBeSaved() {
+		progress.snapshot.Record(key, correct)
+	}
+}
+
+// toggleFlag marks the item to review it later or removes the mark.
+func (progress *sessionProgress) toggleFlag(key string) bool {
+	progress.Lock()
+	defer progress.Unlock()
+	change := historyChange{key: key, toggleFlag: true}
+	change.apply(progress.history)
+	progress.changes = append(progress.changes, change)
+	return progress.history.IsFlagged(key)
+}
+
+// saveHistory replays the changes of the session on the history file and
+// saves it. The history of the session is then the one of the file.
+func (progress *sessionProgress) saveHistory(path string) error {
+	progress.Lock()
+	defer progress.Unlock()
+	saved, err := datamodel.UpdateHistory(path, func(h *datamodel.History) {
+		for _, c := range progress.changes {
+			c.apply(h)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	*progress.history = saved
+	progress.changes = nil
+	return nil
+}
+
+// save writes the snapshot of the session to the data directory.
+func (progress *sessionProgress) save() error {
+	progress.Lock()
+	defer progress.Unlock()
+	progress.snapshot.Elapsed = time.Since(progress.startedAt)
+	progress.snapshot.SavedAt = time.Now()
+	return progress.snapshot.Save(progress.path)
+}
+
+// saveAndTell saves the session and tells the user how to resume it.
+func (progress *sessionProgress) saveAndTell(out io.Writer) {
+	if err := progress.save(); err != nil {
+		tools.Error(err, "failed to save the session")
+		return
+	}
+	fmt.Fprintf(out, "Session saved. Run 'repeatit resume' to go on from here.\n")
+}
+
+// watchInterrupts saves the session and the history when the user presses
+// Ctrl-C, then exits. Calling the returned function stops the watching.
+// Nothing is watched if the session cannot be saved.
+func watchInterrupts(progress *sessionProgress, out io.Writer, historyFile string) func() {
+	if !progress.canBeSaved() {
+		return func() {}
+	}
+	interrupts := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-interrupts:
+			fmt.Fprintln(out)
+			progress.saveAndTell(out)
+			if historyFile != "" {
+				if err := progress.saveHistory(historyFile); err != nil {
+					tools.Error(err, "failed to save the history")
+				}
+			}
+			os.Exit(interruptedExitCode)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(interrupts)
+		close(done)
+	}
+}
+
+// restoreProgress checks that the snapshot of an interrupted session
+// matches the set of questions and returns the questions already asked in
+// the current loop.
+func restoreProgress(s *datamodel.Snapshot, nbOfQuestions int) (map[int]int, error) {
+	if s.Next < 0 || s.Next >= nbOfQuestions {
+		return nil, fmt.Errorf("the saved session does not match the questions set: question %d out of %d", s.Next, nbOfQuestions)
+	}
+	askedInLoop := make(map[int]int)
+	for _, i := range s.AskedInLoop {
+		if i < 0 || i >= nbOfQuestions {
+			return nil, fmt.Errorf("the saved session does not match the questions set: question %d out of %d", i, nbOfQuestions)
+		}
+		askedInLoop[i] = i
+	}
+	return askedInLoop, nil
+}
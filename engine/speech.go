@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/tools"
+)
+
+// listeningOnlyPrompt replaces the text of the question in listening only
+// mode.
+const listeningOnlyPrompt = "(listen)"
+
+// speak runs the speech command configured for the language to say the
+// text passed in parameter. Nothing is done if the speech is disabled. An
+// error is returned if no command is configured for the language.
+func speak(s datamodel.SpeechSettings, lang, text string) error {
+	if !s.Enabled {
+		return nil
+	}
+	template, voice := s.GetCommandFor(lang)
+	if template == "" {
+		return fmt.Errorf("no speech command is configured for the %s language: set speech.command or speech.commands.%s", lang, strings.ToLower(lang))
+	}
+	return runHook(buildHookCommand(template, map[string]string{
+		datamodel.SpeechLangPlaceholder: voice,
+		datamodel.SpeechTextPlaceholder: text,
+	}))
+}
+
+// speakOrWarn speaks the text and only warns the user if it fails: the
+// session goes on even if the speech synthesis is broken.
+func speakOrWarn(s datamodel.SpeechSettings, lang, text string) {
+	if err := speak(s, lang, text); err != nil {
+		tools.Warning(err.Error())
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/internal/tests"
+	"github.com/boris-lenzinger/repeatit/parsing"
+)
+
+// TestSpeechHook replaces the speech synthesis with a fake command that
+// records its arguments, and checks that the questions and the answers are
+// spoken in the good language.
+func TestSpeechHook(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "spoken.log")
+	fakeSpeech := filepath.Join(dir, "fake-speech")
+	script := "#!/bin/sh\necho \"$1|$2\" >> " + logFile + "\n"
+	if err := ioutil.WriteFile(fakeSpeech, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to create the fake speech command: %v", err)
+	}
+
+	r := strings.NewReader(tests.GetSampleCsvAsStream())
+	topic, err := parsing.ParseTopic(r, datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	questionsSet := topic.BuildVocabularyQuestionsSet("01")
+
+	var out bytes.Buffer
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.SetLimit(1)
+	ip.SetOutputStream(&out)
+	ip.SetLanguages(topic.NativeLanguage, topic.LearnedLanguage)
+	ip.SetSpeechSettings(datamodel.SpeechSettings{Command: fakeSpeech + " {lang} {text}"})
+	ip.SetListeningOnlyMode()
+	if err := AskQuestions(questionsSet, ip); err != nil {
+		t.Fatalf("asking questions must not fail. Received: %v", err)
+	}
+
+	spoken, err := ioutil.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("the fake speech command was not called: %v", err)
+	}
+	expected := "native|1_Question 1\nlearnt|1_Answer 1\n"
+	if string(spoken) != expected {
+		t.Errorf("Expected the speech command to receive %q but got %q", expected, string(spoken))
+	}
+	if strings.Contains(out.String(), "1_Question 1") {
+		t.Errorf("The question must be hidden in listening only mode. Output:\n%s", out.String())
+	}
+}
+
+// TestSpeakWithoutCommand checks that a language without speech command is
+// reported instead of being silently skipped.
+func TestSpeakWithoutCommand(t *testing.T) {
+	s := datamodel.SpeechSettings{Enabled: true, Commands: map[string]string{"french": "true {text}"}}
+	if !s.IsConfigured() {
+		t.Errorf("A command for a language is a configured speech")
+	}
+	if err := speak(s, "French", "Bonjour"); err != nil {
+		t.Errorf("Speaking a configured language must not fail. Received: %v", err)
+	}
+	if err := speak(s, "German", "Hallo"); err == nil {
+		t.Errorf("Speaking a language without command must fail")
+	}
+	if (datamodel.SpeechSettings{Enabled: true}).IsConfigured() {
+		t.Errorf("A speech without command is not configured")
+	}
+}
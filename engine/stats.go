@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// hardestItemsCount is the number of items listed as the hardest ones in
+// the statistics.
+const hardestItemsCount = 5
+
+// writeStats displays, for each lesson, how many items were practised and
+// how many answers were wrong, then the items that are the hardest.
+func writeStats(out io.Writer, topic datamodel.Topic, history datamodel.History, lessonIDs []string) {
+	type hardItem struct {
+		question string
+		key      string
+	}
+	hardest := []hardItem{}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "  lesson\titems\tpractised\tanswers\tmisses\n")
+	for _, ID := range lessonIDs {
+		qa := topic.GetVocabularySubsection(ID)
+		items, practised, answers, misses := qa.GetCount(), 0, 0, 0
+		for i := 0; i < qa.GetCount(); i++ {
+			seen := false
+			for _, d := range []datamodel.Direction{datamodel.Production, datamodel.Recognition} {
+				key := qa.GetDirectionalKey(i, d)
+				r, ok := history.Items[key]
+				if !ok {
+					continue
+				}
+				seen = true
+				answers += r.Asked
+				misses += r.Misses
+				if r.Misses > 0 {
+					hardest = append(hardest, hardItem{question: fmt.Sprintf("%s (%s)", qa.GetQuestion(i), d), key: key})
+				}
+			}
+			if seen {
+				practised++
+			}
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%d\t%d\n", ID, items, practised, answers, misses)
+	}
+	w.Flush()
+	if len(hardest) == 0 {
+		return
+	}
+	sort.SliceStable(hardest, func(i, j int) bool {
+		return history.Weight(hardest[i].key) > history.Weight(hardest[j].key)
+	})
+	if len(hardest) > hardestItemsCount {
+		hardest = hardest[:hardestItemsCount]
+	}
+	fmt.Fprintf(out, "Hardest items:\n")
+	for _, item := range hardest {
+		fmt.Fprintf(out, "  * %s: %s\n", item.question, history.Explain(item.key))
+	}
+}
+
+// searchTopic returns the entries of the topic whose question or answer
+// contains the text, regardless of the case.
+func searchTopic(topic datamodel.Topic, text string) []string {
+	text = strings.ToLower(text)
+	found := []string{}
+	search := func(ID string, qa datamodel.QuestionsAnswers, kind string) {
+		for i := 0; i < qa.GetCount(); i++ {
+			if strings.Contains(strings.ToLower(qa.GetQuestion(i)), text) || strings.Contains(strings.ToLower(qa.GetAnswer(i)), text) {
+				found = append(found, fmt.Sprintf("[%s %s] %s -> %s", ID, kind, qa.GetQuestion(i), qa.GetAnswer(i)))
+			}
+		}
+	}
+	vocabulary := topic.GetVocabularySubsectionsName()
+	sort.Strings(vocabulary)
+	for _, ID := range vocabulary {
+		search(ID, topic.GetVocabularySubsection(ID), "vocabulary")
+	}
+	sentences := topic.GetSentencesSubsectionsName()
+	sort.Strings(sentences)
+	for _, ID := range sentences {
+		search(ID, topic.GetSentencesSubsection(ID), "sentences")
+	}
+	return found
+}
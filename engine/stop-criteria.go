@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// checkStopCriteria tells if the session must stop before the end of the
+// loops. It returns the reason of the stop or an empty string if the
+// session can go on.
+func checkStopCriteria(p datamodel.InterrogationParameters, startedAt time.Time, questionsAsked int) string {
+	if p.GetMaxQuestions() > 0 && questionsAsked >= p.GetMaxQuestions() {
+		return fmt.Sprintf("maximum number of questions reached (%d)", p.GetMaxQuestions())
+	}
+	if p.GetMaxDuration() > 0 && time.Since(startedAt) >= p.GetMaxDuration() {
+		return fmt.Sprintf("maximum duration reached (%s)", p.GetMaxDuration())
+	}
+	return ""
+}
+
+// writeCoverageSummary tells the user what was covered during a session
+// that was cut short and lists the questions that were not asked.
+func writeCoverageSummary(out io.Writer, qa datamodel.QuestionsAnswers, covered map[int]bool, reason string) {
+	fmt.Fprintf(out, "Session stopped: %s\n", reason)
+	fmt.Fprintf(out, "Covered %d of %d questions.\n", len(covered), qa.GetCount())
+	if len(covered) == qa.GetCount() {
+		return
+	}
+	fmt.Fprintf(out, "Not covered:\n")
+	for i := 0; i < qa.GetCount(); i++ {
+		if !covered[i] {
+			fmt.Fprintf(out, "  * %s\n", qa.GetQuestion(i))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// drawWeighted picks the index of a question with a probability that is
+// proportional to its weight, in the history, for the direction of the
+// prompt, scaled by its difficulty. The previous index is excluded, when
+// possible, so the same question is not asked twice in a row.
+func drawWeighted(rng *rand.Rand, qa datamodel.QuestionsAnswers, h datamodel.History, previous int, d datamodel.Direction) int {
+	count := qa.GetCount()
+	weights := make([]float64, count)
+	total := 0.0
+	for i := 0; i < count; i++ {
+		if i == previous && count > 1 {
+			continue
+		}
+		weights[i] = h.Weight(qa.GetDirectionalKey(i, d)) * difficultyFactor(qa.GetDifficulty(i))
+		total += weights[i]
+	}
+	r := rng.Float64() * total
+	for i := 0; i < count; i++ {
+		if weights[i] == 0 {
+			continue
+		}
+		r -= weights[i]
+		if r < 0 {
+			return i
+		}
+	}
+	// rounding errors: return the last eligible index
+	for i := count - 1; i >= 0; i-- {
+		if weights[i] != 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// difficultyFactor scales the weight of an entry by the difficulty set by
+// the author: the hardest entries come back twice as often as the easiest
+// ones. An entry without difficulty is not scaled.
+func difficultyFactor(difficulty int) float64 {
+	if difficulty == 0 {
+		return 1
+	}
+	return 1 + float64(difficulty-datamodel.MinDifficulty)/float64(datamodel.MaxDifficulty-datamodel.MinDifficulty)
+}
+
+// explainWeights writes to out the weight of each question, in each of the
+// directions, and the reasons of this weight so the user understands why
+// an item keeps coming back.
+func explainWeights(out io.Writer, qa datamodel.QuestionsAnswers, h datamodel.History, directions ...datamodel.Direction) {
+	fmt.Fprintf(out, "Weights of the questions:\n")
+	for i := 0; i < qa.GetCount(); i++ {
+		for _, d := range directions {
+			explanation := h.Explain(qa.GetDirectionalKey(i, d))
+			if difficulty := qa.GetDifficulty(i); difficulty != 0 {
+				explanation += fmt.Sprintf(", x%.2f for the difficulty %d", difficultyFactor(difficulty), difficulty)
+			}
+			fmt.Fprintf(out, "  * %s (%s): %s\n", qa.GetQuestion(i), d, explanation)
+		}
+	}
+}
+
+// explainedDirections returns the directions in which the questions can be
+// asked during the session.
+func explainedDirections(p datamodel.InterrogationParameters) []datamodel.Direction {
+	switch {
+	case p.IsMixedDirectionMode():
+		return []datamodel.Direction{datamodel.Production, datamodel.Recognition}
+	case p.IsReversedMode():
+		return []datamodel.Direction{datamodel.Recognition}
+	default:
+		return []datamodel.Direction{datamodel.Production}
+	}
+}
@@ -11,14 +11,14 @@ import (
 func GetSampleCsvAsStream() string {
 	content := fmt.Sprintf(`#native;learnt
 
-%s 1
+%s 01
 1_Question 1;1_Answer 1
 
-%s 2
+%s 02
 2_Question 1;2_Answer 1
 2_Question 2;2_Answer 2
 
-%s 3
+%s 03
 3_Question 1;3_Answer 1
 3_Question 2;3_Answer 2
 3_Question 3;3_Answer 3
@@ -30,8 +30,10 @@ func GetSampleCsvAsStream() string {
 // GetTpp returns topic parsing parameters for testing purpose.
 func GetTpp() datamodel.TopicParsingParameters {
 	return datamodel.TopicParsingParameters{
-		LessonAnnounce:   datamodel.LessonDelimiter,
-		SentenceAnnounce: datamodel.SentencesDelimiter,
-		QaSep:            datamodel.DefaultQaSep,
+		LessonAnnounce:      datamodel.LessonDelimiter,
+		SentenceAnnounce:    datamodel.SentencesDelimiter,
+		GrammarAnnounce:     datamodel.GrammarDelimiter,
+		ConjugationAnnounce: datamodel.ConjugationDelimiter,
+		QaSep:               datamodel.DefaultQaSep,
 	}
 }
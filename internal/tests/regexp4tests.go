@@ -20,3 +20,7 @@ var NbOfQuestions = regexp.MustCompile("^Nb of questions: [0-9]+$")
 // LimitReached is a regular expression to test if a line of text is the annoncement of
 // reaching the maximum number of loops.
 var LimitReached = regexp.MustCompile("^Limit reached. Exiting. Number of loops set to: [0-9]+$")
+
+// Seed is a regular expression to test if a line of text is the announcement of the seed
+// used to draw the questions.
+var Seed = regexp.MustCompile("^Seed: -?[0-9]+$")
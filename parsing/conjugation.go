@@ -0,0 +1,50 @@
+package parsing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// conjugationPersonsKeyword starts the line declaring the persons of the
+// tables that follow in a conjugation section. A table is a compact row:
+//
+//	persons;je;tu;il;nous;vous;ils
+//	aller;futur;irai;iras;ira;irons;irez;iront
+//
+// A form written - is a person the verb has no form for.
+const conjugationPersonsKeyword = "persons"
+
+// missingForm marks a person the verb has no form for.
+const missingForm = "-"
+
+// parseConjugationLine reads a line of a conjugation section. It returns
+// the persons of the next tables, updated if the line declares them, and
+// the table of the line if it is one.
+func parseConjugationLine(line string, qaSep string, persons []string) ([]string, *datamodel.ConjugationTable, error) {
+	split := strings.Split(line, qaSep)
+	for i := range split {
+		split[i] = strings.TrimSpace(split[i])
+	}
+	if split[0] == conjugationPersonsKeyword {
+		if len(split) < 2 {
+			return persons, nil, fmt.Errorf("no person is declared in %q", line)
+		}
+		return split[1:], nil, nil
+	}
+	if len(split) < 3 {
+		return persons, nil, fmt.Errorf("a conjugation table must be written verb%stense%sforms. Found %q", qaSep, qaSep, line)
+	}
+	forms := split[2:]
+	for i := range forms {
+		if forms[i] == missingForm {
+			forms[i] = ""
+		}
+	}
+	table, err := datamodel.NewConjugationTable(split[0], split[1], persons, forms)
+	if err != nil {
+		return persons, nil, err
+	}
+	return persons, &table, nil
+}
@@ -0,0 +1,84 @@
+package parsing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+const (
+	// bookHeader announces the title of the book in the header of the
+	// lessons file: #book:Assimil German
+	bookHeader = "#book:"
+	// authorHeader announces an author of the book: #author:First Last.
+	// The line can be repeated for each author.
+	authorHeader = "#author:"
+	// isbnHeader announces the ISBN of the book: #isbn:978-2-7005-0000-0
+	isbnHeader = "#isbn:"
+)
+
+// idMarkup matches the identifier of an entry in the lessons file. The
+// syntax is [id:house].
+var idMarkup = regexp.MustCompile(`\s*\[id:([^\]]*)\]`)
+
+// titleMarkup matches the title of a lesson in the lessons file. The
+// syntax is [title:native;learnt] where ; is the q/a separator.
+var titleMarkup = regexp.MustCompile(`\s*\[title:([^\]]*)\]`)
+
+// extractID removes the identifier markup from a line and returns the line
+// without it and the identifier. If there is no markup, the returned
+// identifier is empty.
+func extractID(line string) (string, string, error) {
+	found := idMarkup.FindStringSubmatch(line)
+	if found == nil {
+		return line, "", nil
+	}
+	ID := strings.TrimSpace(found[1])
+	if ID == "" {
+		return line, "", fmt.Errorf("the identifier in %q is empty", line)
+	}
+	return idMarkup.ReplaceAllString(line, ""), ID, nil
+}
+
+// extractTitle removes the title markup from a line and returns the line
+// without it and the title. If there is no markup, the returned title is
+// empty.
+func extractTitle(line string, qaSep string) (string, datamodel.Resource, error) {
+	found := titleMarkup.FindStringSubmatch(line)
+	if found == nil {
+		return line, datamodel.Resource{}, nil
+	}
+	split := strings.SplitN(found[1], qaSep, 2)
+	if len(split) != 2 {
+		return line, datamodel.Resource{}, fmt.Errorf("the title in %q must match 'native%slearnt'", line, qaSep)
+	}
+	title := datamodel.Resource{
+		Native:   strings.TrimSpace(split[0]),
+		Learning: strings.TrimSpace(split[1]),
+	}
+	return titleMarkup.ReplaceAllString(line, ""), title, nil
+}
+
+// parseBookLine fills the book with a line of the header of the lessons
+// file. The boolean is false if the line is not about the book.
+func parseBookLine(book *datamodel.Book, line string) bool {
+	switch {
+	case strings.HasPrefix(line, bookHeader):
+		book.Title = strings.TrimSpace(strings.TrimPrefix(line, bookHeader))
+	case strings.HasPrefix(line, isbnHeader):
+		book.ISBN = strings.TrimSpace(strings.TrimPrefix(line, isbnHeader))
+	case strings.HasPrefix(line, authorHeader):
+		names := strings.Fields(strings.TrimPrefix(line, authorHeader))
+		author := datamodel.Author{}
+		if len(names) > 0 {
+			author.Firstname = strings.Join(names[:len(names)-1], " ")
+			author.Lastname = names[len(names)-1]
+		}
+		book.Authors = append(book.Authors, author)
+	default:
+		return false
+	}
+	return true
+}
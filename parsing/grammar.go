@@ -0,0 +1,75 @@
+package parsing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// Prefixes of the lines of a grammar section in the lessons file:
+//
+//	## Word order after weil
+//	After weil, the conjugated verb goes to the end of the clause.
+//	> I stay because it rains.;Ich bleibe, weil es regnet.
+//	? Ich bleibe, weil es ____. (regnen);regnet
+//	= Er kommt. (weil);weil er kommt
+//
+// The title starts a new rule. The lines without prefix are the
+// explanation, the examples and the exercises are question/answer pairs.
+const (
+	grammarTitlePrefix          = "## "
+	grammarExamplePrefix        = "> "
+	grammarFillInPrefix         = "? "
+	grammarTransformationPrefix = "= "
+)
+
+// addGrammarLine adds a line of a grammar section to the rules of the
+// lesson and returns the updated rules. An error is returned if the line
+// comes before the title of the first rule or if an example or an
+// exercise has no answer.
+func addGrammarLine(rules []datamodel.GrammarRule, line string, qaSep string) ([]datamodel.GrammarRule, error) {
+	if strings.HasPrefix(line, grammarTitlePrefix) {
+		return append(rules, datamodel.GrammarRule{Title: strings.TrimSpace(strings.TrimPrefix(line, grammarTitlePrefix))}), nil
+	}
+	if len(rules) == 0 {
+		return rules, fmt.Errorf("a grammar rule must start with its title: %q", grammarTitlePrefix+"title")
+	}
+	rule := &rules[len(rules)-1]
+	switch {
+	case strings.HasPrefix(line, grammarExamplePrefix):
+		native, learnt, err := splitGrammarPair(strings.TrimPrefix(line, grammarExamplePrefix), qaSep)
+		if err != nil {
+			return rules, err
+		}
+		rule.Examples = append(rule.Examples, datamodel.Resource{Native: native, Learning: learnt})
+	case strings.HasPrefix(line, grammarFillInPrefix):
+		prompt, answer, err := splitGrammarPair(strings.TrimPrefix(line, grammarFillInPrefix), qaSep)
+		if err != nil {
+			return rules, err
+		}
+		rule.Exercises = append(rule.Exercises, datamodel.GrammarExercise{Kind: datamodel.FillInExercise, Prompt: prompt, Answer: answer})
+	case strings.HasPrefix(line, grammarTransformationPrefix):
+		prompt, answer, err := splitGrammarPair(strings.TrimPrefix(line, grammarTransformationPrefix), qaSep)
+		if err != nil {
+			return rules, err
+		}
+		rule.Exercises = append(rule.Exercises, datamodel.GrammarExercise{Kind: datamodel.TransformationExercise, Prompt: prompt, Answer: answer})
+	default:
+		if rule.Explanation != "" {
+			rule.Explanation += "\n"
+		}
+		rule.Explanation += strings.TrimSpace(line)
+	}
+	return rules, nil
+}
+
+// splitGrammarPair separates the two sides of an example or an exercise.
+// As for the entries, the answer may contain the separator.
+func splitGrammarPair(line string, qaSep string) (string, string, error) {
+	split := strings.Split(line, qaSep)
+	if len(split) < 2 {
+		return "", "", fmt.Errorf("%q has no answer after %q", line, qaSep)
+	}
+	return strings.TrimSpace(split[0]), strings.TrimSpace(strings.Join(split[1:], qaSep)), nil
+}
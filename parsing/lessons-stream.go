@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/boris-lenzinger/repeatit/datamodel"
@@ -13,21 +14,44 @@ import (
 )
 
 // ParseLanguageFile is reading a file on disk and builds a Topic based on
-// the content of file. Any underlying error encountered is reported.
+// the content of file. A file with the .json extension is read as the JSON
+// format of the lessons (see datamodel.Language), any other file as the
+// text format. Any underlying error encountered is reported.
 func ParseLanguageFile(pathToFile string, p datamodel.TopicParsingParameters) (datamodel.Topic, error) {
 	f, err := os.Open(pathToFile)
 	if err != nil {
 		return datamodel.Topic{}, errors.Wrapf(err, "error while opening the lang file %q", pathToFile)
 	}
+	defer f.Close()
+	if IsJSONFile(pathToFile) {
+		l, err := datamodel.LoadLessons(f)
+		if err != nil {
+			return datamodel.NewTopic(), errors.Wrapf(err, "error while reading the lang file %q", pathToFile)
+		}
+		return l.ToTopic()
+	}
 	return ParseTopic(f, p)
 }
 
+// IsJSONFile tells if the lessons file is in the JSON format.
+func IsJSONFile(pathToFile string) bool {
+	return strings.ToLower(filepath.Ext(pathToFile)) == ".json"
+}
+
 // ParseTopic is reading the data source and transforms it to a topic
 // structure.
 func ParseTopic(r io.Reader, p datamodel.TopicParsingParameters) (datamodel.Topic, error) {
 	if p.LessonAnnounce == "" || p.QaSep == "" || p.SentenceAnnounce == "" {
 		return datamodel.Topic{}, fmt.Errorf("One of the lesson announce, sentence announce or q/a separators is empty. Parsing of file will fail")
 	}
+	grammarAnnounce := p.GrammarAnnounce
+	if grammarAnnounce == "" {
+		grammarAnnounce = datamodel.GrammarDelimiter
+	}
+	conjugationAnnounce := p.ConjugationAnnounce
+	if conjugationAnnounce == "" {
+		conjugationAnnounce = datamodel.ConjugationDelimiter
+	}
 	// Reading the file line by line
 	s := bufio.NewScanner(r)
 
@@ -39,7 +63,12 @@ func ParseTopic(r io.Reader, p datamodel.TopicParsingParameters) (datamodel.Topi
 	topic := datamodel.NewTopic()
 	var subsectionID string
 	qaSubsection := datamodel.NewQA()
-	var isVocabularySection, isSentencesSection bool
+	var isVocabularySection, isSentencesSection, isGrammarSection, isConjugationSection bool
+	// persons of the conjugation tables of the current section
+	var persons []string
+	// tags and difficulty of the current lesson, inherited by its entries
+	var lessonTags []string
+	var lessonDifficulty int
 	for i := 0; i < len(lines); i++ {
 		input := lines[i]
 		if i == 0 {
@@ -54,8 +83,78 @@ func ParseTopic(r io.Reader, p datamodel.TopicParsingParameters) (datamodel.Topi
 			topic.LearnedLanguage = strings.Trim(splitted[1], " ")
 			continue
 		}
+		// The description of the book follows the header
+		if parseBookLine(&topic.Book, input) {
+			continue
+		}
 		// Ignore empty lines
 		if len(input) > 0 {
+			// the lines of the grammar and conjugation sections have their
+			// own syntax up to the next lesson or sentences delimiter
+			if strings.HasPrefix(input, grammarAnnounce) {
+				tools.Debug(fmt.Sprintf("Found grammar delimiter: %s", input))
+				subsectionID = strings.Trim(strings.TrimPrefix(input, grammarAnnounce), " ")
+				isVocabularySection = false
+				isSentencesSection = false
+				isGrammarSection = true
+				isConjugationSection = false
+				continue
+			}
+			if strings.HasPrefix(input, conjugationAnnounce) {
+				tools.Debug(fmt.Sprintf("Found conjugation delimiter: %s", input))
+				subsectionID = strings.Trim(strings.TrimPrefix(input, conjugationAnnounce), " ")
+				isVocabularySection = false
+				isSentencesSection = false
+				isGrammarSection = false
+				isConjugationSection = true
+				persons = datamodel.DefaultPersons
+				continue
+			}
+			isOtherSection := strings.HasPrefix(input, p.LessonAnnounce) || strings.HasPrefix(input, p.SentenceAnnounce)
+			if isGrammarSection && !isOtherSection {
+				rules, err := addGrammarLine(topic.GetGrammar(subsectionID), input, p.QaSep)
+				if err != nil {
+					return datamodel.NewTopic(), errors.Wrapf(err, "line %d", i+1)
+				}
+				topic.SetGrammar(subsectionID, rules)
+				continue
+			}
+			if isConjugationSection && !isOtherSection {
+				var table *datamodel.ConjugationTable
+				var err error
+				persons, table, err = parseConjugationLine(input, p.QaSep, persons)
+				if err != nil {
+					return datamodel.NewTopic(), errors.Wrapf(err, "line %d", i+1)
+				}
+				if table != nil {
+					topic.AddConjugation(subsectionID, *table)
+				}
+				continue
+			}
+			input, media, err := extractMedia(input)
+			if err != nil {
+				return datamodel.NewTopic(), errors.Wrapf(err, "line %d", i+1)
+			}
+			input, tags, err := extractTags(input)
+			if err != nil {
+				return datamodel.NewTopic(), errors.Wrapf(err, "line %d", i+1)
+			}
+			input, difficulty, err := extractDifficulty(input)
+			if err != nil {
+				return datamodel.NewTopic(), errors.Wrapf(err, "line %d", i+1)
+			}
+			input, word, err := extractWordInfo(input)
+			if err != nil {
+				return datamodel.NewTopic(), errors.Wrapf(err, "line %d", i+1)
+			}
+			input, ID, err := extractID(input)
+			if err != nil {
+				return datamodel.NewTopic(), errors.Wrapf(err, "line %d", i+1)
+			}
+			input, title, err := extractTitle(input, p.QaSep)
+			if err != nil {
+				return datamodel.NewTopic(), errors.Wrapf(err, "line %d", i+1)
+			}
 			split := strings.Split(input, p.QaSep)
 			switch len(split) {
 			// Length of split is not 1. This means that there no separator.
@@ -68,19 +167,57 @@ func ParseTopic(r io.Reader, p datamodel.TopicParsingParameters) (datamodel.Topi
 					qaSubsection = topic.GetVocabularySubsection(subsectionID)
 					isVocabularySection = true
 					isSentencesSection = false
+					isGrammarSection = false
+					isConjugationSection = false
+					lessonTags, lessonDifficulty = tags, difficulty
+					if title.Native != "" || title.Learning != "" {
+						topic.SetLessonTitle(subsectionID, title)
+					}
+					if len(tags) > 0 || difficulty != 0 {
+						topic.SetLessonTags(subsectionID, tags, difficulty)
+					}
 				} else if strings.HasPrefix(input, p.SentenceAnnounce) {
 					tools.Debug(fmt.Sprintf("Found sentences delimiter: %s", input))
-					subsectionID = strings.TrimPrefix(input, p.SentenceAnnounce)
+					subsectionID = strings.Trim(strings.TrimPrefix(input, p.SentenceAnnounce), " ")
 					qaSubsection = topic.GetSentencesSubsection(subsectionID)
 					isVocabularySection = false
 					isSentencesSection = true
+					isGrammarSection = false
+					isConjugationSection = false
+					lessonTags, lessonDifficulty = tags, difficulty
 				}
 			default:
 				// Question is in split[0] while answer in in split[1]. It may happen
 				// the answer contains the separator so we have to join the different
 				// elements.
 				tools.Debug(fmt.Sprintf("Adding entry %s", split[0]))
-				qaSubsection.AddEntry(split[0], strings.Join(split[1:], p.QaSep))
+				kind := datamodel.Vocabulary
+				if isSentencesSection {
+					kind = datamodel.Sentence
+				}
+				answer := strings.Join(split[1:], p.QaSep)
+				variants := strings.Split(answer, datamodel.VariantsSep)
+				for v := 0; len(variants) > 1 && v < len(variants); v++ {
+					variants[v] = strings.TrimSpace(variants[v])
+				}
+				answer = variants[0]
+				qaSubsection.AddEntryOfKind(split[0], datamodel.StripClozeMarkup(answer), kind)
+				if len(variants) > 1 {
+					qaSubsection.SetVariants(qaSubsection.GetCount()-1, variants[1:])
+				}
+				if media != nil {
+					qaSubsection.SetMedia(qaSubsection.GetCount()-1, media)
+				}
+				qaSubsection.SetWordInfo(qaSubsection.GetCount()-1, word)
+				qaSubsection.SetID(qaSubsection.GetCount()-1, ID)
+				qaSubsection.SetTags(qaSubsection.GetCount()-1, append(append([]string{}, lessonTags...), tags...))
+				if difficulty == 0 {
+					difficulty = lessonDifficulty
+				}
+				qaSubsection.SetDifficulty(qaSubsection.GetCount()-1, difficulty)
+				if datamodel.HasClozeMarkup(answer) {
+					qaSubsection.SetCloze(qaSubsection.GetCount()-1, answer)
+				}
 				if isVocabularySection {
 					topic.SetVocabularySubsection(subsectionID, qaSubsection)
 					topic.IncreaseVocabularyCount()
@@ -2,10 +2,10 @@ package parsing
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/boris-lenzinger/repeatit/datamodel"
 	"github.com/boris-lenzinger/repeatit/internal/tests"
 )
 
@@ -31,7 +31,7 @@ func TestParseStream(t *testing.T) {
 	}
 
 	for i := 1; i <= 3; i++ {
-		qa = topic.BuildVocabularyQuestionsSet(strconv.Itoa(i))
+		qa = topic.BuildVocabularyQuestionsSet(datamodel.FormatLessonID(i))
 		count = qa.GetCount()
 		if count != i {
 			fmt.Printf("QuestionsAnswers set: %v\n", qa)
@@ -41,3 +41,258 @@ func TestParseStream(t *testing.T) {
 	}
 
 }
+
+// TestParseStreamWithMedia checks that the audio clips referenced in the
+// lines are attached to the entries and removed from their text.
+func TestParseStreamWithMedia(t *testing.T) {
+	content := `#native;learnt
+### Lesson 1
+house;Haus [audio:clips/l1.mp3|1.5|3]
+cat;Katze [audio:katze.ogg]
+dog;Hund
+`
+	topic, err := ParseTopic(strings.NewReader(content), tests.GetTpp())
+	if err != nil {
+		t.Fatalf("parsing of topic should not raise an error. Get %v", err)
+	}
+	qa := topic.BuildVocabularyQuestionsSet("1")
+	if qa.GetAnswer(0) != "Haus" {
+		t.Errorf("The audio markup must be removed from the answer. Got %q", qa.GetAnswer(0))
+	}
+	m := qa.GetMedia(0)
+	if m == nil || m.Path != "clips/l1.mp3" || m.Start != 1.5 || m.End != 3 {
+		t.Errorf("Expected clip clips/l1.mp3 from 1.5 to 3 but got %+v", m)
+	}
+	if m = qa.GetMedia(1); m == nil || m.Path != "katze.ogg" || m.Start != 0 || m.End != 0 {
+		t.Errorf("Expected the whole clip katze.ogg but got %+v", m)
+	}
+	if m = qa.GetMedia(2); m != nil {
+		t.Errorf("Expected no clip but got %+v", m)
+	}
+
+	_, err = ParseTopic(strings.NewReader("#native;learnt\n### Lesson 1\nhouse;Haus [audio:h.mp3|3|1]\n"), tests.GetTpp())
+	if err == nil {
+		t.Errorf("A clip that ends before it starts must be reported")
+	}
+}
+
+// TestParseStreamWithVariants checks that the acceptable variants of an
+// answer are separated from the answer.
+func TestParseStreamWithVariants(t *testing.T) {
+	content := `#native;learnt
+### Sentences Lesson 1
+The cat sleeps.;Le chat dort. || Il dort, le chat.
+`
+	topic, err := ParseTopic(strings.NewReader(content), tests.GetTpp())
+	if err != nil {
+		t.Fatalf("parsing of topic should not raise an error. Get %v", err)
+	}
+	qa := topic.BuildSentencesQuestionsSet("1")
+	if qa.GetCount() != 1 {
+		t.Fatalf("Expected 1 sentence but got %d", qa.GetCount())
+	}
+	if qa.GetAnswer(0) != "Le chat dort." {
+		t.Errorf("Expected answer %q but got %q", "Le chat dort.", qa.GetAnswer(0))
+	}
+	variants := qa.GetVariants(0)
+	if len(variants) != 1 || variants[0] != "Il dort, le chat." {
+		t.Errorf("Expected variant %q but got %v", "Il dort, le chat.", variants)
+	}
+}
+
+// TestParseStreamWithTags checks that the entries get their own tags and
+// difficulty and inherit the ones of their lesson.
+func TestParseStreamWithTags(t *testing.T) {
+	content := `#native;learnt
+### Lesson 1 [tags:verb] [difficulty:2]
+to be;sein [tags:irregular] [difficulty:4]
+to play;spielen
+### Lesson 2
+bread;Brot [tags:food]
+`
+	topic, err := ParseTopic(strings.NewReader(content), tests.GetTpp())
+	if err != nil {
+		t.Fatalf("parsing of topic should not raise an error. Get %v", err)
+	}
+	qa := topic.BuildVocabularyQuestionsSet("1", "2")
+	if qa.GetCount() != 3 || qa.GetAnswer(0) != "sein" {
+		t.Fatalf("The markup must be removed from the entries. Got %d entries, first answer %q", qa.GetCount(), qa.GetAnswer(0))
+	}
+	expected := []struct {
+		tags       string
+		difficulty int
+	}{
+		{"verb,irregular", 4},
+		{"verb", 2},
+		{"food", 0},
+	}
+	for i, e := range expected {
+		if got := strings.Join(qa.GetTags(i), ","); got != e.tags {
+			t.Errorf("entry %d: expected tags %q but got %q", i, e.tags, got)
+		}
+		if qa.GetDifficulty(i) != e.difficulty {
+			t.Errorf("entry %d: expected difficulty %d but got %d", i, e.difficulty, qa.GetDifficulty(i))
+		}
+	}
+
+	_, err = ParseTopic(strings.NewReader("#native;learnt\n### Lesson 1\nhouse;Haus [difficulty:9]\n"), tests.GetTpp())
+	if err == nil {
+		t.Errorf("A difficulty out of range must be reported")
+	}
+}
+
+// TestParseStreamWithWordInfo checks that the grammatical information of
+// the words is read and removed from the answers.
+func TestParseStreamWithWordInfo(t *testing.T) {
+	content := `#native;learnt
+### Lesson 1
+house;Haus [gender:n] [plural:Häuser]
+to go;gehen [pos:verb] [forms:ging, gegangen]
+dog;Hund
+`
+	topic, err := ParseTopic(strings.NewReader(content), tests.GetTpp())
+	if err != nil {
+		t.Fatalf("parsing of topic should not raise an error. Get %v", err)
+	}
+	qa := topic.BuildVocabularyQuestionsSet("1")
+	if qa.GetAnswer(0) != "Haus" || qa.GetAnswer(1) != "gehen" {
+		t.Errorf("The markup must be removed from the answers. Got %q and %q", qa.GetAnswer(0), qa.GetAnswer(1))
+	}
+	w := qa.GetWordInfo(0)
+	if w == nil || w.PartOfSpeech != "noun" || w.Gender != datamodel.Neuter || w.Plural != "Häuser" {
+		t.Errorf("Expected a neuter noun with the plural Häuser but got %+v", w)
+	}
+	w = qa.GetWordInfo(1)
+	if w == nil || w.PartOfSpeech != "verb" || len(w.Forms) != 2 || w.Forms[1] != "gegangen" {
+		t.Errorf("Expected a verb with the forms ging and gegangen but got %+v", w)
+	}
+	if w = qa.GetWordInfo(2); w != nil {
+		t.Errorf("Expected no grammatical information but got %+v", w)
+	}
+
+	_, err = ParseTopic(strings.NewReader("#native;learnt\n### Lesson 1\nhouse;Haus [gender:x]\n"), tests.GetTpp())
+	if err == nil {
+		t.Errorf("An unknown gender must be reported")
+	}
+}
+
+// TestParseStreamWithGrammar checks that the rules of a grammar section
+// are read up to the next lesson.
+func TestParseStreamWithGrammar(t *testing.T) {
+	content := `#native;learnt
+### Grammar Lesson 1
+## Word order after weil
+After weil, the conjugated verb
+goes to the end of the clause.
+> I stay because it rains.;Ich bleibe, weil es regnet.
+? Ich bleibe, weil es ____. (regnen);regnet
+= Er kommt. (weil);weil er kommt
+## Articles
+### Lesson 1
+to rain;regnen
+`
+	topic, err := ParseTopic(strings.NewReader(content), tests.GetTpp())
+	if err != nil {
+		t.Fatalf("parsing of topic should not raise an error. Get %v", err)
+	}
+	rules := topic.GetGrammar("1")
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 grammar rules but got %d", len(rules))
+	}
+	rule := rules[0]
+	if rule.Title != "Word order after weil" || rule.Explanation != "After weil, the conjugated verb\ngoes to the end of the clause." {
+		t.Errorf("Unexpected title or explanation: %+v", rule)
+	}
+	if len(rule.Examples) != 1 || rule.Examples[0].Learning != "Ich bleibe, weil es regnet." || rule.Examples[0].Native != "I stay because it rains." {
+		t.Errorf("Unexpected examples: %+v", rule.Examples)
+	}
+	expected := []datamodel.GrammarExercise{
+		{Kind: datamodel.FillInExercise, Prompt: "Ich bleibe, weil es ____. (regnen)", Answer: "regnet"},
+		{Kind: datamodel.TransformationExercise, Prompt: "Er kommt. (weil)", Answer: "weil er kommt"},
+	}
+	if len(rule.Exercises) != len(expected) {
+		t.Fatalf("Expected %d exercises but got %+v", len(expected), rule.Exercises)
+	}
+	for i, e := range expected {
+		if rule.Exercises[i] != e {
+			t.Errorf("Expected exercise %+v but got %+v", e, rule.Exercises[i])
+		}
+	}
+	if topic.BuildVocabularyQuestionsSet("1").GetCount() != 1 {
+		t.Errorf("The lesson after the grammar section must be read")
+	}
+	if qa := topic.BuildGrammarQuestionsSet("1"); qa.GetCount() != 2 || qa.GetAnswer(1) != "weil er kommt" {
+		t.Errorf("Expected the 2 exercises as questions but got %d", qa.GetCount())
+	}
+
+	_, err = ParseTopic(strings.NewReader("#native;learnt\n### Grammar Lesson 1\nno title\n"), tests.GetTpp())
+	if err == nil {
+		t.Errorf("A rule without title must be reported")
+	}
+}
+
+// TestParseStreamWithConjugations checks that the conjugation tables are
+// read with the persons declared in their section.
+func TestParseStreamWithConjugations(t *testing.T) {
+	content := `#native;learnt
+### Conjugation Lesson 1
+persons;je;tu;il;nous;vous;ils
+aller;futur;irai;iras;ira;irons;irez;iront
+pleuvoir;présent;-;-;pleut;-;-;-
+### Conjugation Lesson 2
+sein;Präsens;bin;bist;ist;sind;seid;sind
+`
+	topic, err := ParseTopic(strings.NewReader(content), tests.GetTpp())
+	if err != nil {
+		t.Fatalf("parsing of topic should not raise an error. Get %v", err)
+	}
+	tables := topic.GetConjugations("1")
+	if len(tables) != 2 {
+		t.Fatalf("Expected 2 tables but got %d", len(tables))
+	}
+	if tables[0].Verb != "aller" || tables[0].Tense != "futur" || tables[0].Persons[3] != "nous" || tables[0].Forms[3] != "irons" {
+		t.Errorf("Unexpected table: %+v", tables[0])
+	}
+	if tables[1].Forms[0] != "" || tables[1].Forms[2] != "pleut" {
+		t.Errorf("The forms written - must be empty. Got %v", tables[1].Forms)
+	}
+	tables = topic.GetConjugations("2")
+	if len(tables) != 1 || tables[0].Persons[0] != datamodel.DefaultPersons[0] {
+		t.Errorf("A section without persons must use the default ones. Got %+v", tables)
+	}
+
+	_, err = ParseTopic(strings.NewReader("#native;learnt\n### Conjugation Lesson 1\naller;futur;irai;iras\n"), tests.GetTpp())
+	if err == nil {
+		t.Errorf("A table without one form per person must be reported")
+	}
+}
+
+func TestParseStreamWithContentMetadata(t *testing.T) {
+	content := `#native;learnt
+#book:German with ease
+#author:Hilde Schneider
+#isbn:978-2-7005-0000-0
+### Lesson 1 [title:At the restaurant;Im Restaurant]
+house;Haus [id:house]
+to eat;essen
+`
+	topic, err := ParseTopic(strings.NewReader(content), tests.GetTpp())
+	if err != nil {
+		t.Fatalf("parsing of topic should not raise an error. Get %v", err)
+	}
+	if topic.Book.Title != "German with ease" || topic.Book.ISBN != "978-2-7005-0000-0" || len(topic.Book.Authors) != 1 || topic.Book.Authors[0].Lastname != "Schneider" {
+		t.Errorf("Unexpected book: %+v", topic.Book)
+	}
+	if title := topic.GetLessonTitle("1"); title.Native != "At the restaurant" || title.Learning != "Im Restaurant" {
+		t.Errorf("Unexpected title: %+v", title)
+	}
+	qa := topic.GetVocabularySubsection("1")
+	if qa.GetAnswer(0) != "Haus" || qa.GetKey(0) != "house" || qa.GetID(1) != "" {
+		t.Errorf("Unexpected entries: %q with key %q", qa.GetAnswer(0), qa.GetKey(0))
+	}
+
+	_, err = ParseTopic(strings.NewReader("#native;learnt\n### Lesson 1 [title:no separator]\n"), tests.GetTpp())
+	if err == nil {
+		t.Errorf("A title without the translation must be reported")
+	}
+}
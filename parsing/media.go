@@ -0,0 +1,43 @@
+package parsing
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// mediaMarkup matches the reference to an audio clip in a line of the
+// lessons file. The syntax is [audio:path], [audio:path|start] or
+// [audio:path|start|end] where start and end are offsets in seconds.
+var mediaMarkup = regexp.MustCompile(`\s*\[audio:([^\]|]+)(?:\|([^\]|]*))?(?:\|([^\]|]*))?\]`)
+
+// extractMedia removes the audio clip markup from a line and returns the
+// line without it and the clip. If there is no markup, the returned clip
+// is nil.
+func extractMedia(line string) (string, *datamodel.Media, error) {
+	found := mediaMarkup.FindStringSubmatch(line)
+	if found == nil {
+		return line, nil, nil
+	}
+	m := &datamodel.Media{Path: strings.TrimSpace(found[1])}
+	var err error
+	if found[2] != "" {
+		m.Start, err = strconv.ParseFloat(strings.TrimSpace(found[2]), 64)
+		if err != nil {
+			return line, nil, fmt.Errorf("the start offset of the audio clip in %q is not a number of seconds", line)
+		}
+	}
+	if found[3] != "" {
+		m.End, err = strconv.ParseFloat(strings.TrimSpace(found[3]), 64)
+		if err != nil {
+			return line, nil, fmt.Errorf("the end offset of the audio clip in %q is not a number of seconds", line)
+		}
+	}
+	if m.End != 0 && m.End <= m.Start {
+		return line, nil, fmt.Errorf("the audio clip in %q ends before it starts", line)
+	}
+	return mediaMarkup.ReplaceAllString(line, ""), m, nil
+}
@@ -0,0 +1,50 @@
+package parsing
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// tagsMarkup matches the tags of an entry or a lesson in the lessons file.
+// The syntax is [tags:verb,irregular].
+var tagsMarkup = regexp.MustCompile(`\s*\[tags:([^\]]*)\]`)
+
+// difficultyMarkup matches the difficulty of an entry or a lesson in the
+// lessons file. The syntax is [difficulty:3].
+var difficultyMarkup = regexp.MustCompile(`\s*\[difficulty:([^\]]*)\]`)
+
+// extractTags removes the tags markup from a line and returns the line
+// without it and the tags. If there is no markup, the returned tags are nil.
+func extractTags(line string) (string, []string, error) {
+	found := tagsMarkup.FindStringSubmatch(line)
+	if found == nil {
+		return line, nil, nil
+	}
+	tags := datamodel.NormalizeTags(strings.Split(found[1], ","))
+	if err := datamodel.CheckTags(tags); err != nil {
+		return line, nil, err
+	}
+	return tagsMarkup.ReplaceAllString(line, ""), tags, nil
+}
+
+// extractDifficulty removes the difficulty markup from a line and returns
+// the line without it and the difficulty. If there is no markup, the
+// returned difficulty is 0.
+func extractDifficulty(line string) (string, int, error) {
+	found := difficultyMarkup.FindStringSubmatch(line)
+	if found == nil {
+		return line, 0, nil
+	}
+	difficulty, err := strconv.Atoi(strings.TrimSpace(found[1]))
+	if err != nil {
+		return line, 0, fmt.Errorf("the difficulty in %q is not a number", line)
+	}
+	if err := datamodel.CheckDifficulty(difficulty); err != nil {
+		return line, 0, err
+	}
+	return difficultyMarkup.ReplaceAllString(line, ""), difficulty, nil
+}
@@ -0,0 +1,49 @@
+package parsing
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+)
+
+// wordMarkup matches the grammatical information of a vocabulary entry in
+// the lessons file: [pos:noun], [gender:n], [plural:Häuser] and
+// [forms:ging,gegangen] for the irregular forms.
+var wordMarkup = regexp.MustCompile(`\s*\[(pos|gender|plural|forms):([^\]]*)\]`)
+
+// extractWordInfo removes the grammatical markup from a line and returns
+// the line without it and the information found. If there is no markup,
+// the returned information is nil. A gender implies the word is a noun.
+func extractWordInfo(line string) (string, *datamodel.WordInfo, error) {
+	found := wordMarkup.FindAllStringSubmatch(line, -1)
+	if found == nil {
+		return line, nil, nil
+	}
+	w := &datamodel.WordInfo{}
+	for _, m := range found {
+		value := strings.TrimSpace(m[2])
+		switch m[1] {
+		case "pos":
+			w.PartOfSpeech = strings.ToLower(value)
+		case "gender":
+			g, err := datamodel.ParseGender(value)
+			if err != nil {
+				return line, nil, err
+			}
+			w.Gender = g
+		case "plural":
+			w.Plural = value
+		case "forms":
+			for _, f := range strings.Split(value, ",") {
+				if f = strings.TrimSpace(f); f != "" {
+					w.Forms = append(w.Forms, f)
+				}
+			}
+		}
+	}
+	if w.Gender != "" && w.PartOfSpeech == "" {
+		w.PartOfSpeech = "noun"
+	}
+	return wordMarkup.ReplaceAllString(line, ""), w, nil
+}
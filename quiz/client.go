@@ -0,0 +1,133 @@
+package quiz
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// dialTimeout is the time given to reach the host.
+const dialTimeout = 5 * time.Second
+
+// Join takes part in the quiz hosted at addr under the name passed in
+// parameter. Each line read from in answers the current question: only the
+// first line counts. What the host sends is displayed on out. It returns
+// once the quiz is over.
+func Join(addr string, name string, in io.Reader, out io.Writer) error {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return errors.Wrapf(err, "failed to join the quiz on %q", addr)
+	}
+	defer conn.Close()
+	c := &client{enc: json.NewEncoder(conn), out: out}
+	if err := c.send(message{Type: joinMessage, Name: name}); err != nil {
+		return errors.Wrap(err, "failed to join the quiz")
+	}
+	go c.readAnswers(in)
+
+	dec := json.NewDecoder(conn)
+	for {
+		var m message
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("the host has stopped the quiz")
+			}
+			return errors.Wrap(err, "the connection to the host is lost")
+		}
+		switch m.Type {
+		case welcomeMessage:
+			c.printf("Joined as %s. Waiting for the host to start the quiz...\n", m.Name)
+		case playersMessage:
+			c.printf("Players: %s\n", strings.Join(m.Players, ", "))
+		case questionMessage:
+			c.ask(m)
+		case answeredMessage:
+			c.printf("  %s has answered\n", m.Name)
+		case resultMessage:
+			c.closeQuestion(m)
+		case endMessage:
+			c.Lock()
+			writeScoreboard(out, "Final scoreboard", m.Scoreboard)
+			c.Unlock()
+			return nil
+		case errorMessage:
+			return fmt.Errorf("the host refused: %s", m.Error)
+		}
+	}
+}
+
+// client is a player connected to the host.
+type client struct {
+	enc *json.Encoder
+	out io.Writer
+	// writing serializes the messages sent to the host
+	writing sync.Mutex
+
+	// protects out and the question being asked
+	sync.Mutex
+	// number of the question that can be answered, zero if none
+	current int
+}
+
+// send writes a message to the host.
+func (c *client) send(m message) error {
+	c.writing.Lock()
+	defer c.writing.Unlock()
+	return c.enc.Encode(m)
+}
+
+// printf displays a line for the player.
+func (c *client) printf(format string, args ...interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	fmt.Fprintf(c.out, format, args...)
+}
+
+// ask displays the question and lets the player answer it.
+func (c *client) ask(m message) {
+	c.Lock()
+	defer c.Unlock()
+	c.current = m.Number
+	writeQuestion(c.out, m)
+}
+
+// closeQuestion displays the results of the question. It cannot be
+// answered anymore.
+func (c *client) closeQuestion(m message) {
+	c.Lock()
+	defer c.Unlock()
+	c.current = 0
+	writeResult(c.out, m)
+}
+
+// readAnswers sends the lines typed by the player as answers to the
+// current question.
+func (c *client) readAnswers(in io.Reader) {
+	s := bufio.NewScanner(in)
+	for s.Scan() {
+		attempt := strings.TrimSpace(s.Text())
+		if attempt == "" {
+			continue
+		}
+		c.Lock()
+		number := c.current
+		c.current = 0
+		if number == 0 {
+			fmt.Fprintf(c.out, "Please wait for the next question.\n")
+		}
+		c.Unlock()
+		if number == 0 {
+			continue
+		}
+		if err := c.send(message{Type: answerMessage, Number: number, Attempt: attempt}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package quiz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultAnswerTime is the time given to the players to answer a
+	// question.
+	DefaultAnswerTime = 20 * time.Second
+	// DefaultResultPause is the time the results of a question are shown
+	// before the next question is asked.
+	DefaultResultPause = 3 * time.Second
+	// basePoints are won for a correct answer
+	basePoints = 100
+	// maxSpeedBonus is added to a correct answer given at once. It
+	// decreases to zero at the end of the time to answer.
+	maxSpeedBonus = 100
+	// writeTimeout keeps a player who does not read its messages from
+	// blocking the quiz
+	writeTimeout = 5 * time.Second
+)
+
+// player is a participant connected to the host.
+type player struct {
+	name  string
+	conn  net.Conn
+	score int
+	left  bool
+
+	// serializes the messages sent to the player
+	writing sync.Mutex
+	enc     *json.Encoder
+}
+
+// send writes a message to the player.
+func (pl *player) send(m message) error {
+	pl.writing.Lock()
+	defer pl.writing.Unlock()
+	pl.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return pl.enc.Encode(m)
+}
+
+// playerAnswer is an answer received from a player, or the notice that the
+// player has left.
+type playerAnswer struct {
+	player     *player
+	number     int
+	attempt    string
+	receivedAt time.Time
+	left       bool
+}
+
+// Host asks the questions of a set to the players connected to it.
+type Host struct {
+	qa  datamodel.QuestionsAnswers
+	p   datamodel.InterrogationParameters
+	out io.Writer
+	// serializes what is displayed on out
+	displaying  sync.Mutex
+	answerTime  time.Duration
+	resultPause time.Duration
+
+	listener  net.Listener
+	answers   chan playerAnswer
+	done      chan struct{}
+	closeOnce sync.Once
+
+	sync.Mutex
+	players []*player
+}
+
+// NewHost creates a host for a quiz on the set of questions. The
+// parameters tell in which order the questions are asked (linear or
+// random), in which direction, and how many of them if a maximum number of
+// questions is set. What happens is displayed on out.
+func NewHost(qa datamodel.QuestionsAnswers, p datamodel.InterrogationParameters, out io.Writer) *Host {
+	return &Host{
+		qa:          qa,
+		p:           p,
+		out:         out,
+		answerTime:  DefaultAnswerTime,
+		resultPause: DefaultResultPause,
+		answers:     make(chan playerAnswer),
+		done:        make(chan struct{}),
+	}
+}
+
+// SetAnswerTime changes the time given to answer a question.
+func (h *Host) SetAnswerTime(d time.Duration) {
+	h.answerTime = d
+}
+
+// SetResultPause changes the time the results of a question are shown
+// before the next question.
+func (h *Host) SetResultPause(d time.Duration) {
+	h.resultPause = d
+}
+
+// Listen starts accepting the players on the address. They can join until
+// the quiz is over.
+func (h *Host) Listen(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %q", addr)
+	}
+	h.listener = listener
+	go h.accept()
+	return nil
+}
+
+// GetAddr returns the address the players join.
+func (h *Host) GetAddr() string {
+	return h.listener.Addr().String()
+}
+
+// GetPlayers returns the names of the players still connected.
+func (h *Host) GetPlayers() []string {
+	h.Lock()
+	defer h.Unlock()
+	names := []string{}
+	for _, pl := range h.players {
+		if !pl.left {
+			names = append(names, pl.name)
+		}
+	}
+	return names
+}
+
+// GetScoreboard returns the scores of the players, the best first.
+func (h *Host) GetScoreboard() []Score {
+	h.Lock()
+	defer h.Unlock()
+	scores := make([]Score, 0, len(h.players))
+	for _, pl := range h.players {
+		scores = append(scores, Score{Name: pl.name, Points: pl.score, Left: pl.left})
+	}
+	sortScores(scores)
+	return scores
+}
+
+// Play asks the questions one after the other. Each question ends when all
+// the players have answered or when the time to answer is over. The final
+// scoreboard is sent to the players at the end.
+func (h *Host) Play() error {
+	if len(h.GetPlayers()) == 0 {
+		return fmt.Errorf("no player has joined the quiz")
+	}
+	order := h.buildOrder()
+	for n, i := range order {
+		d := h.p.PickDirection()
+		if datamodel.IsOneWayExercise(h.p.GetExercise()) || h.qa.GetScrambledWords(i) != nil {
+			d = datamodel.Production
+		}
+		question, answer := h.qa.GetQuestion(i), h.qa.GetAnswer(i)
+		if d == datamodel.Recognition {
+			question, answer = answer, question
+		}
+		asked := message{
+			Type:     questionMessage,
+			Number:   n + 1,
+			Total:    len(order),
+			Question: question,
+			Seconds:  int(h.answerTime.Seconds()),
+		}
+		h.display(func(out io.Writer) { writeQuestion(out, asked) })
+		h.broadcast(asked)
+		results := h.collectAnswers(asked.Number, i, d)
+		result := message{
+			Type:       resultMessage,
+			Number:     asked.Number,
+			Answer:     answer,
+			Results:    results,
+			Scoreboard: h.GetScoreboard(),
+		}
+		h.display(func(out io.Writer) { writeResult(out, result) })
+		h.broadcast(result)
+		if n < len(order)-1 {
+			time.Sleep(h.resultPause)
+		}
+	}
+	scoreboard := h.GetScoreboard()
+	h.display(func(out io.Writer) { writeScoreboard(out, "Final scoreboard", scoreboard) })
+	h.broadcast(message{Type: endMessage, Scoreboard: scoreboard})
+	return nil
+}
+
+// Close stops accepting players and disconnects them.
+func (h *Host) Close() error {
+	var err error
+	h.closeOnce.Do(func() {
+		close(h.done)
+		if h.listener != nil {
+			err = h.listener.Close()
+		}
+		h.Lock()
+		defer h.Unlock()
+		for _, pl := range h.players {
+			pl.conn.Close()
+		}
+	})
+	return err
+}
+
+// buildOrder returns the indices of the questions in the order they are
+// asked.
+func (h *Host) buildOrder() []int {
+	n := h.qa.GetCount()
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	if h.p.IsRandomMode() || h.p.IsWeightedMode() {
+		order = h.p.GetRandom().Perm(n)
+	}
+	if max := h.p.GetMaxQuestions(); max > 0 && max < n {
+		order = order[:max]
+	}
+	return order
+}
+
+// collectAnswers grades the answers to the question until all the players
+// have answered or the time is over. Only the first answer of a player
+// counts.
+func (h *Host) collectAnswers(number int, i int, d datamodel.Direction) []AnswerResult {
+	askedAt := time.Now()
+	timeout := time.After(h.answerTime)
+	results := []AnswerResult{}
+	answered := make(map[*player]bool)
+	for !h.haveAllAnswered(answered) {
+		select {
+		case <-timeout:
+			return results
+		case a := <-h.answers:
+			if a.left || a.number != number || answered[a.player] {
+				continue
+			}
+			correct := engine.GradeAttempt(h.qa, i, d, a.attempt)
+			points := computePoints(correct, a.receivedAt.Sub(askedAt), h.answerTime)
+			h.Lock()
+			a.player.score += points
+			h.Unlock()
+			answered[a.player] = true
+			results = append(results, AnswerResult{Name: a.player.name, Attempt: a.attempt, Correct: correct, Points: points})
+			h.broadcast(message{Type: answeredMessage, Name: a.player.name, Number: number})
+		}
+	}
+	return results
+}
+
+// haveAllAnswered tells if the players still connected have all answered.
+func (h *Host) haveAllAnswered(answered map[*player]bool) bool {
+	h.Lock()
+	defer h.Unlock()
+	for _, pl := range h.players {
+		if !pl.left && !answered[pl] {
+			return false
+		}
+	}
+	return true
+}
+
+// computePoints returns the points won by an answer: nothing if it is
+// wrong, the base points and a bonus for the speed otherwise.
+func computePoints(correct bool, elapsed time.Duration, answerTime time.Duration) int {
+	if !correct {
+		return 0
+	}
+	remaining := answerTime - elapsed
+	if remaining < 0 {
+		remaining = 0
+	}
+	return basePoints + int(float64(maxSpeedBonus)*float64(remaining)/float64(answerTime))
+}
+
+// accept handles the players who connect until the host is closed.
+func (h *Host) accept() {
+	for {
+		conn, err := h.listener.Accept()
+		if err != nil {
+			return
+		}
+		go h.handle(conn)
+	}
+}
+
+// handle registers the player connected and forwards its answers to the
+// quiz. The first message must be the request to join.
+func (h *Host) handle(conn net.Conn) {
+	dec := json.NewDecoder(conn)
+	pl := &player{conn: conn, enc: json.NewEncoder(conn)}
+	var join message
+	if err := dec.Decode(&join); err != nil || join.Type != joinMessage {
+		pl.send(message{Type: errorMessage, Error: "the first message must be a request to join"})
+		conn.Close()
+		return
+	}
+	if !h.register(pl, join.Name) {
+		pl.send(message{Type: errorMessage, Error: "the quiz is over"})
+		conn.Close()
+		return
+	}
+	for {
+		var m message
+		if err := dec.Decode(&m); err != nil {
+			break
+		}
+		if m.Type != answerMessage {
+			continue
+		}
+		select {
+		case h.answers <- playerAnswer{player: pl, number: m.Number, attempt: strings.TrimSpace(m.Attempt), receivedAt: time.Now()}:
+		case <-h.done:
+			return
+		}
+	}
+	h.unregister(pl)
+	// the question being asked must not wait for the player anymore
+	select {
+	case h.answers <- playerAnswer{player: pl, left: true}:
+	case <-h.done:
+	}
+}
+
+// register adds the player under a name no other player has. It returns
+// false if the quiz is over.
+func (h *Host) register(pl *player, name string) bool {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "player"
+	}
+	h.Lock()
+	select {
+	case <-h.done:
+		h.Unlock()
+		return false
+	default:
+	}
+	pl.name = name
+	for n := 2; h.isNameTaken(pl.name); n++ {
+		pl.name = fmt.Sprintf("%s (%d)", name, n)
+	}
+	h.players = append(h.players, pl)
+	h.Unlock()
+
+	h.display(func(out io.Writer) { fmt.Fprintf(out, "%s has joined the quiz\n", pl.name) })
+	pl.send(message{Type: welcomeMessage, Name: pl.name})
+	h.broadcast(message{Type: playersMessage, Players: h.GetPlayers()})
+	return true
+}
+
+// isNameTaken tells if a player already has the name. The lock must be
+// held.
+func (h *Host) isNameTaken(name string) bool {
+	for _, pl := range h.players {
+		if pl.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unregister marks the player as gone. Its score stays on the scoreboard.
+func (h *Host) unregister(pl *player) {
+	h.Lock()
+	pl.left = true
+	h.Unlock()
+	pl.conn.Close()
+	select {
+	case <-h.done:
+		return
+	default:
+	}
+	h.display(func(out io.Writer) { fmt.Fprintf(out, "%s has left the quiz\n", pl.name) })
+	h.broadcast(message{Type: playersMessage, Players: h.GetPlayers()})
+}
+
+// display writes to the output of the host, one writer at a time.
+func (h *Host) display(write func(out io.Writer)) {
+	h.displaying.Lock()
+	defer h.displaying.Unlock()
+	write(h.out)
+}
+
+// broadcast sends the message to the players still connected. A player who
+// cannot receive it is disconnected.
+func (h *Host) broadcast(m message) {
+	h.Lock()
+	players := make([]*player, 0, len(h.players))
+	for _, pl := range h.players {
+		if !pl.left {
+			players = append(players, pl)
+		}
+	}
+	h.Unlock()
+	for _, pl := range players {
+		if err := pl.send(m); err != nil {
+			pl.conn.Close()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package quiz runs a quiz on the local network: a host asks the questions
+// of a selection of lessons to the players who joined over TCP. Everyone
+// gets the same question at the same time and scores by answering
+// correctly and quickly.
+//
+// The host and the players exchange JSON messages, one per line.
+package quiz
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Types of the messages exchanged between the host and the players.
+const (
+	// sent by a player to join the quiz, with its name
+	joinMessage = "join"
+	// sent by a player to answer the question of the number
+	answerMessage = "answer"
+	// sent to a player once joined, with the name it was given
+	welcomeMessage = "welcome"
+	// sent to everyone when a player joins or leaves
+	playersMessage = "players"
+	// sent to everyone when a question is asked
+	questionMessage = "question"
+	// sent to everyone when a player has answered the question
+	answeredMessage = "answered"
+	// sent to everyone when the time to answer is over
+	resultMessage = "result"
+	// sent to everyone at the end of the quiz
+	endMessage = "end"
+	// sent to a player whose request is refused
+	errorMessage = "error"
+)
+
+// message is what the host and the players send each other. Only the
+// fields that make sense for the type of message are set.
+type message struct {
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Number   int    `json:"number,omitempty"`
+	Total    int    `json:"total,omitempty"`
+	Question string `json:"question,omitempty"`
+	// Seconds is the time given to answer the question
+	Seconds    int            `json:"seconds,omitempty"`
+	Attempt    string         `json:"attempt,omitempty"`
+	Answer     string         `json:"answer,omitempty"`
+	Results    []AnswerResult `json:"results,omitempty"`
+	Scoreboard []Score        `json:"scoreboard,omitempty"`
+	Players    []string       `json:"players,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// AnswerResult is the answer of a player to a question.
+type AnswerResult struct {
+	Name    string `json:"name"`
+	Attempt string `json:"attempt"`
+	Correct bool   `json:"correct"`
+	Points  int    `json:"points"`
+}
+
+// Score is the total of the points of a player.
+type Score struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+	// Left tells that the player has left the quiz
+	Left bool `json:"left,omitempty"`
+}
+
+// sortScores ranks the players: the highest score first, the names break
+// the ties.
+func sortScores(scores []Score) {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Points != scores[j].Points {
+			return scores[i].Points > scores[j].Points
+		}
+		return scores[i].Name < scores[j].Name
+	})
+}
+
+// writeQuestion displays a question.
+func writeQuestion(out io.Writer, m message) {
+	fmt.Fprintf(out, "Question %d/%d (%ds): %s\n", m.Number, m.Total, m.Seconds, m.Question)
+}
+
+// writeResult displays the answer of a question and what each player
+// answered, followed by the scoreboard.
+func writeResult(out io.Writer, m message) {
+	fmt.Fprintf(out, "     --> %s\n", m.Answer)
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, r := range m.Results {
+		verdict := "wrong"
+		if r.Correct {
+			verdict = "correct"
+		}
+		fmt.Fprintf(w, "  %s\t%q\t%s\t+%d\n", r.Name, r.Attempt, verdict, r.Points)
+	}
+	w.Flush()
+	writeScoreboard(out, "Scoreboard", m.Scoreboard)
+}
+
+// writeScoreboard displays the ranking of the players.
+func writeScoreboard(out io.Writer, title string, scores []Score) {
+	fmt.Fprintf(out, "%s:\n", title)
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for rank, s := range scores {
+		left := ""
+		if s.Left {
+			left = "(left)"
+		}
+		fmt.Fprintf(w, "  %d.\t%s\t%d\t%s\n", rank+1, s.Name, s.Points, left)
+	}
+	w.Flush()
+	fmt.Fprintln(out, strings.Repeat("-", 27))
+}
@@ -0,0 +1,169 @@
+package quiz
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/internal/tests"
+	"github.com/boris-lenzinger/repeatit/parsing"
+)
+
+// testPlayer is a loopback client speaking the protocol directly.
+type testPlayer struct {
+	t    *testing.T
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+func joinAs(t *testing.T, addr string, name string) *testPlayer {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("joining the quiz must not fail. Received: %v", err)
+	}
+	pl := &testPlayer{t: t, conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(conn)}
+	pl.send(message{Type: joinMessage, Name: name})
+	return pl
+}
+
+func (pl *testPlayer) send(m message) {
+	if err := pl.enc.Encode(m); err != nil {
+		pl.t.Fatalf("sending %+v must not fail. Received: %v", m, err)
+	}
+}
+
+// waitFor reads the messages until one matches.
+func (pl *testPlayer) waitFor(matches func(m message) bool) message {
+	pl.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var m message
+		if err := pl.dec.Decode(&m); err != nil {
+			pl.t.Fatalf("waiting for a message must not fail. Received: %v", err)
+		}
+		if matches(m) {
+			return m
+		}
+	}
+}
+
+func ofType(messageType string) func(m message) bool {
+	return func(m message) bool {
+		return m.Type == messageType
+	}
+}
+
+func TestQuizWithLoopbackPlayers(t *testing.T) {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	p := datamodel.NewInterrogationParameters()
+	p.SetLinearMode()
+	h := NewHost(topic.BuildVocabularyQuestionsSet("02"), p, ioutil.Discard)
+	// a player who leaves must not make the others wait for the time out
+	h.SetAnswerTime(time.Minute)
+	h.SetResultPause(0)
+	if err := h.Play(); err == nil {
+		t.Errorf("A quiz without player must not start")
+	}
+	if err := h.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("listening must not fail. Received: %v", err)
+	}
+	defer h.Close()
+
+	anna := joinAs(t, h.GetAddr(), "anna")
+	anna.waitFor(ofType(welcomeMessage))
+	bob := joinAs(t, h.GetAddr(), "anna")
+	if welcome := bob.waitFor(ofType(welcomeMessage)); welcome.Name != "anna (2)" {
+		t.Errorf("Two players must not have the same name. Got %q", welcome.Name)
+	}
+	anna.waitFor(func(m message) bool { return m.Type == playersMessage && len(m.Players) == 2 })
+
+	played := make(chan error)
+	go func() {
+		played <- h.Play()
+	}()
+
+	for _, pl := range []*testPlayer{anna, bob} {
+		if q := pl.waitFor(ofType(questionMessage)); q.Question != "2_Question 1" || q.Number != 1 || q.Total != 2 {
+			t.Fatalf("Expected the first question but got %+v", q)
+		}
+	}
+	anna.send(message{Type: answerMessage, Number: 1, Attempt: "2_answer 1"})
+	bob.send(message{Type: answerMessage, Number: 1, Attempt: "nope"})
+	result := anna.waitFor(ofType(resultMessage))
+	if result.Answer != "2_Answer 1" || len(result.Results) != 2 {
+		t.Fatalf("Expected the answers of both players but got %+v", result)
+	}
+	for _, r := range result.Results {
+		if r.Name == "anna" && (!r.Correct || r.Points <= basePoints) {
+			t.Errorf("A quick correct answer must win a speed bonus. Got %+v", r)
+		}
+		if r.Name == "anna (2)" && (r.Correct || r.Points != 0) {
+			t.Errorf("A wrong answer must not win points. Got %+v", r)
+		}
+	}
+
+	bob.conn.Close()
+	anna.waitFor(ofType(questionMessage))
+	anna.send(message{Type: answerMessage, Number: 2, Attempt: "2_Answer 2"})
+	end := anna.waitFor(ofType(endMessage))
+	if len(end.Scoreboard) != 2 || end.Scoreboard[0].Name != "anna" || !end.Scoreboard[1].Left {
+		t.Errorf("Expected anna first and the player who left second but got %+v", end.Scoreboard)
+	}
+	select {
+	case err := <-played:
+		if err != nil {
+			t.Errorf("playing must not fail. Received: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the quiz must be over once the players still connected have answered")
+	}
+}
+
+func TestComputePoints(t *testing.T) {
+	if p := computePoints(false, 0, time.Second); p != 0 {
+		t.Errorf("A wrong answer must win nothing. Got %d", p)
+	}
+	if p := computePoints(true, 0, time.Second); p != basePoints+maxSpeedBonus {
+		t.Errorf("An immediate answer must win the full bonus. Got %d", p)
+	}
+	if p := computePoints(true, 2*time.Second, time.Second); p != basePoints {
+		t.Errorf("A late answer must only win the base points. Got %d", p)
+	}
+}
+
+// TestQuizAsksScrambleInProduction checks that a reversed quiz still asks
+// the shuffled words of the sentences.
+func TestQuizAsksScrambleInProduction(t *testing.T) {
+	sentences := datamodel.NewQA()
+	sentences.AddEntryOfKind("The cat sleeps.", "Le chat dort.", datamodel.Sentence)
+	topic := datamodel.NewTopic()
+	topic.SetSentencesSubsection("01", sentences)
+	p := datamodel.NewInterrogationParameters()
+	p.SetLinearMode()
+	p.SetReverseMode()
+	p.SetExercise(datamodel.ExerciseScramble)
+	h := NewHost(topic.BuildScrambleQuestionsSet(p.GetRandom(), "01"), p, ioutil.Discard)
+	h.SetResultPause(0)
+	if err := h.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("listening must not fail. Received: %v", err)
+	}
+	defer h.Close()
+	anna := joinAs(t, h.GetAddr(), "anna")
+	anna.waitFor(ofType(welcomeMessage))
+
+	go h.Play()
+	if q := anna.waitFor(ofType(questionMessage)); !strings.HasSuffix(q.Question, "(The cat sleeps.)") {
+		t.Fatalf("Expected the shuffled words but got %q", q.Question)
+	}
+	anna.send(message{Type: answerMessage, Number: 1, Attempt: "le chat dort."})
+	if result := anna.waitFor(ofType(resultMessage)); result.Answer != "Le chat dort." || len(result.Results) != 1 || !result.Results[0].Correct {
+		t.Errorf("The sentence put back in order must be correct. Got %+v", result)
+	}
+}
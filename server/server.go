@@ -0,0 +1,538 @@
+// Package server exposes the lessons and the study sessions through a JSON
+// API over HTTP, and serves a small web page to study in a browser.
+package server
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/parsing"
+	"github.com/boris-lenzinger/repeatit/tools"
+)
+
+// indexPage is the web page served on /.
+//
+//go:embed index.html
+var indexPage []byte
+
+const (
+	lessonsPath  = "/api/lessons"
+	sessionsPath = "/api/sessions"
+)
+
+const (
+	// SessionIdleTimeout is the time after which a session nobody uses is
+	// closed and forgotten. Its results are saved.
+	SessionIdleTimeout = 2 * time.Hour
+	// FinishedSessionRetention is the time a finished session is kept so
+	// its results can still be read.
+	FinishedSessionRetention = 10 * time.Minute
+	// readHeaderTimeout and readTimeout limit the time given to a client
+	// to send its request.
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+)
+
+// Server serves the API on a topic. Each session started through the API
+// gets a copy of the parameters of the server.
+type Server struct {
+	topic  datamodel.Topic
+	params datamodel.InterrogationParameters
+
+	sync.Mutex
+	sessions map[string]*sessionEntry
+	// the sessions unused for this time are evicted
+	idleTimeout     time.Duration
+	finishedTimeout time.Duration
+}
+
+// sessionEntry is a session started through the API. Its lock serializes
+// the requests on the session since a session is not safe for concurrent
+// use.
+type sessionEntry struct {
+	sync.Mutex
+	session *engine.Session
+	// events sends the events of the session to the streams opened on it
+	events  *engine.Broadcaster
+	card    engine.Card
+	started bool
+	closed  bool
+	// lastUsed is the time of the last request on the session
+	lastUsed time.Time
+	// evicted tells the requests already waiting for the session that it
+	// is gone
+	evicted bool
+}
+
+// New creates a server on the topic. The parameters are the default
+// settings of the sessions: mode, limit, data directory...
+func New(topic datamodel.Topic, p datamodel.InterrogationParameters) *Server {
+	return &Server{
+		topic:    topic,
+		params:   p,
+		sessions:        make(map[string]*sessionEntry),
+		idleTimeout:     SessionIdleTimeout,
+		finishedTimeout: FinishedSessionRetention,
+	}
+}
+
+// Handler returns the handler of the web page and of the API:
+//   - GET  /                              the web page
+//   - GET  /api/lessons                   the lessons of the topic
+//   - GET  /api/sessions                  the running sessions
+//   - POST /api/sessions                  starts a session
+//   - POST /api/sessions/{id}/next        draws the next card
+//   - POST /api/sessions/{id}/answer      grades an attempt
+//   - POST /api/sessions/{id}/reveal      reveals the answer
+//   - GET  /api/sessions/{id}/stats       the results of the session
+//   - GET  /api/sessions/{id}/events      the events of the session, as
+//     server-sent events
+//   - DELETE /api/sessions/{id}           ends the session
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveIndex)
+	mux.HandleFunc(lessonsPath, s.serveLessons)
+	mux.HandleFunc(sessionsPath, s.serveSessions)
+	mux.HandleFunc(sessionsPath+"/", s.serveSession)
+	return mux
+}
+
+// ListenAndServe serves the API on the address until it fails.
+func (s *Server) ListenAndServe(addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+	}
+	return server.ListenAndServe()
+}
+
+// evictSessions closes and forgets the sessions that are finished or
+// unused for too long, so a long running server does not keep them all.
+// The finished sessions are kept a little so their results can be read.
+func (s *Server) evictSessions() {
+	s.Lock()
+	entries := make(map[string]*sessionEntry, len(s.sessions))
+	for id, entry := range s.sessions {
+		entries[id] = entry
+	}
+	s.Unlock()
+	now := time.Now()
+	for id, entry := range entries {
+		entry.Lock()
+		timeout := s.idleTimeout
+		if entry.closed {
+			timeout = s.finishedTimeout
+		}
+		if now.Sub(entry.lastUsed) > timeout {
+			if err := entry.close(); err != nil {
+				tools.Warning(fmt.Sprintf("the results of the session %s are lost: %v", id, err))
+			}
+			entry.evicted = true
+			s.Lock()
+			delete(s.sessions, id)
+			s.Unlock()
+		}
+		entry.Unlock()
+	}
+}
+
+// serveIndex returns the web page.
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexPage)
+}
+
+// lessonInfo describes a lesson for the API.
+type lessonInfo struct {
+	ID        string             `json:"id"`
+	Title     datamodel.Resource `json:"title"`
+	Words     int                `json:"words"`
+	Sentences int                `json:"sentences"`
+}
+
+// serveLessons lists the lessons of the topic.
+func (s *Server) serveLessons(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not allowed on %s", r.Method, r.URL.Path))
+		return
+	}
+	lessons := []lessonInfo{}
+	sentences := s.topic.GetSentencesSubsectionsName()
+	sort.Strings(sentences)
+	for _, ID := range s.topic.GetAllSubsectionsName() {
+		info := lessonInfo{ID: ID, Title: s.topic.GetLessonTitle(ID), Words: s.topic.GetVocabularySubsection(ID).GetCount()}
+		if i := sort.SearchStrings(sentences, ID); i < len(sentences) && sentences[i] == ID {
+			info.Sentences = s.topic.GetSentencesSubsection(ID).GetCount()
+		}
+		lessons = append(lessons, info)
+	}
+	writeJSON(w, http.StatusOK, lessons)
+}
+
+// startRequest is the body of the request starting a session. Only the
+// lessons are mandatory: the other settings default to the ones of the
+// server.
+type startRequest struct {
+	Lessons  string `json:"lessons"`
+	Exercise string `json:"exercise"`
+	Mode     string `json:"mode"`
+	Reverse  bool   `json:"reverse"`
+	Limit    int    `json:"limit"`
+	Seed     *int64 `json:"seed"`
+	// Tags overrides the tag filter of the server, e.g. verb,!irregular
+	Tags string `json:"tags"`
+	// Difficulty selects the entries on their difficulty, e.g. 1:3
+	Difficulty string `json:"difficulty"`
+}
+
+// startResponse is returned when a session is started.
+type startResponse struct {
+	ID        string `json:"id"`
+	Questions int    `json:"questions"`
+	Limit     int    `json:"limit"`
+	Seed      int64  `json:"seed"`
+}
+
+// sessionInfo describes a running session for the API.
+type sessionInfo struct {
+	ID        string `json:"id"`
+	Questions int    `json:"questions"`
+	Asked     int    `json:"asked"`
+	Loop      int    `json:"loop"`
+	Limit     int    `json:"limit"`
+}
+
+// serveSessions lists the running sessions or starts a session.
+func (s *Server) serveSessions(w http.ResponseWriter, r *http.Request) {
+	s.evictSessions()
+	if r.Method == http.MethodGet {
+		s.listSessions(w)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not allowed on %s", r.Method, r.URL.Path))
+		return
+	}
+	var req startRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		return
+	}
+	p, err := s.buildParameters(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	events := engine.NewBroadcaster()
+	session, err := engine.NewSession(s.topic, p, events.Publish)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	id, err := newSessionID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.Lock()
+	s.sessions[id] = &sessionEntry{session: session, events: events, lastUsed: time.Now()}
+	s.Unlock()
+	writeJSON(w, http.StatusCreated, startResponse{
+		ID:        id,
+		Questions: session.GetQuestionsCount(),
+		Limit:     session.GetLimit(),
+		Seed:      p.GetSeed(),
+	})
+}
+
+// listSessions returns the sessions started and not yet deleted, sorted by
+// ID.
+func (s *Server) listSessions(w http.ResponseWriter) {
+	s.Lock()
+	entries := make(map[string]*sessionEntry, len(s.sessions))
+	for id, entry := range s.sessions {
+		entries[id] = entry
+	}
+	s.Unlock()
+	sessions := []sessionInfo{}
+	for id, entry := range entries {
+		entry.Lock()
+		sessions = append(sessions, sessionInfo{
+			ID:        id,
+			Questions: entry.session.GetQuestionsCount(),
+			Asked:     entry.session.GetQuestionsAsked(),
+			Loop:      entry.session.GetLoop(),
+			Limit:     entry.session.GetLimit(),
+		})
+		entry.Unlock()
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+// buildParameters applies the settings of the request to a copy of the
+// parameters of the server.
+func (s *Server) buildParameters(req startRequest) (datamodel.InterrogationParameters, error) {
+	p := s.params
+	p.ResetChannels()
+	selectedLessons, err := parsing.ParseNumberSerie(req.Lessons)
+	if err != nil {
+		return p, fmt.Errorf("invalid lessons %q: %v", req.Lessons, err)
+	}
+	p.SetListOfSubsections(datamodel.ToLessonIDs(selectedLessons)...)
+	switch {
+	case req.Exercise == "":
+		p.SetExercise(datamodel.ExerciseVocabulary)
+	case datamodel.IsExercise(req.Exercise):
+		p.SetExercise(req.Exercise)
+	default:
+		return p, fmt.Errorf("%q is not an exercise. Exercises are %s", req.Exercise, strings.Join(datamodel.Exercises, ", "))
+	}
+	switch req.Mode {
+	case "":
+	case "linear":
+		p.SetLinearMode()
+	case "random":
+		p.SetRandomMode()
+	case "weighted":
+		p.SetWeightedMode()
+	default:
+		return p, fmt.Errorf("%q is not a mode. Modes are linear, random and weighted", req.Mode)
+	}
+	if req.Reverse {
+		p.SetReverseMode()
+	}
+	if req.Limit < 0 {
+		return p, fmt.Errorf("the limit must be a positive number of loops. Received %d", req.Limit)
+	}
+	if req.Limit > 0 {
+		p.SetLimit(req.Limit)
+	}
+	// each session gets its own random source: the sessions are driven
+	// concurrently and the seed returned must replay the session
+	if req.Seed != nil {
+		p.SetSeed(*req.Seed)
+	} else {
+		p.SetSeed(time.Now().UTC().UnixNano())
+	}
+	if req.Tags != "" {
+		f, err := datamodel.ParseTagFilter(req.Tags)
+		if err != nil {
+			return p, err
+		}
+		p.SetTagFilter(f)
+	}
+	if req.Difficulty != "" {
+		r, err := datamodel.ParseDifficultyRange(req.Difficulty)
+		if err != nil {
+			return p, err
+		}
+		p.SetDifficultyRange(r)
+	}
+	p.SetLanguages(s.topic.NativeLanguage, s.topic.LearnedLanguage)
+	return p, nil
+}
+
+// cardResponse is a card of a session.
+type cardResponse struct {
+	Over      bool   `json:"over"`
+	Question  string `json:"question,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	Loop      int    `json:"loop"`
+	Limit     int    `json:"limit"`
+	Asked     int    `json:"asked"`
+	Questions int    `json:"questions"`
+}
+
+// answerRequest is the body of the request grading an attempt.
+type answerRequest struct {
+	Attempt string `json:"attempt"`
+}
+
+// answerResponse tells if the attempt was correct and gives the answer.
+type answerResponse struct {
+	Correct bool   `json:"correct"`
+	Answer  string `json:"answer"`
+}
+
+// statsResponse gives the results of a session for each direction.
+type statsResponse struct {
+	Asked   int                        `json:"asked"`
+	Loop    int                        `json:"loop"`
+	Limit   int                        `json:"limit"`
+	Over    bool                       `json:"over"`
+	Results map[string]directionResult `json:"results"`
+}
+
+// directionResult counts the graded answers in a direction.
+type directionResult struct {
+	Correct int `json:"correct"`
+	Asked   int `json:"asked"`
+}
+
+// serveSession handles the requests on a started session.
+func (s *Server) serveSession(w http.ResponseWriter, r *http.Request) {
+	s.evictSessions()
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, sessionsPath), "/"), "/")
+	id, action := parts[0], ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	s.Lock()
+	entry, ok := s.sessions[id]
+	s.Unlock()
+	if !ok || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no session %q", id))
+		return
+	}
+	if action == "events" && r.Method == http.MethodGet {
+		// the stream lasts as long as the session: it must not keep the
+		// session locked
+		engine.StreamEvents(w, r, entry.events)
+		return
+	}
+	entry.Lock()
+	defer entry.Unlock()
+	if entry.evicted {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no session %q", id))
+		return
+	}
+	entry.lastUsed = time.Now()
+
+	switch {
+	case action == "" && r.Method == http.MethodDelete:
+		s.Lock()
+		delete(s.sessions, id)
+		s.Unlock()
+		if err := entry.close(); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case action == "next" && r.Method == http.MethodPost:
+		card, ok := entry.session.Next()
+		entry.card, entry.started = card, ok
+		if !ok {
+			// the results are saved as soon as the session is over
+			if err := entry.close(); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, entry.cardResponse(ok))
+	case action == "answer" && r.Method == http.MethodPost:
+		var req answerRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+			return
+		}
+		correct, err := entry.session.Answer(req.Attempt)
+		if err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		answer, err := entry.session.Reveal()
+		if err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, answerResponse{Correct: correct, Answer: answer})
+	case action == "reveal" && r.Method == http.MethodPost:
+		answer, err := entry.session.Reveal()
+		if err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, answerResponse{Answer: answer})
+	case action == "stats" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, entry.stats())
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s %s is not supported", r.Method, r.URL.Path))
+	}
+}
+
+// close ends the session once: the results are saved in the history and
+// the event streams are closed.
+func (e *sessionEntry) close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	defer e.events.Close()
+	return e.session.Close()
+}
+
+// cardResponse describes the current card of the session.
+func (e *sessionEntry) cardResponse(ok bool) cardResponse {
+	resp := cardResponse{
+		Over:      !ok,
+		Loop:      e.session.GetLoop(),
+		Limit:     e.session.GetLimit(),
+		Asked:     e.session.GetQuestionsAsked(),
+		Questions: e.session.GetQuestionsCount(),
+	}
+	if ok {
+		resp.Question = e.card.Question
+		resp.Direction = e.card.Direction.String()
+	}
+	return resp
+}
+
+// stats returns the results of the session.
+func (e *sessionEntry) stats() statsResponse {
+	resp := statsResponse{
+		Asked:   e.session.GetQuestionsAsked(),
+		Loop:    e.session.GetLoop(),
+		Limit:   e.session.GetLimit(),
+		Over:    e.session.IsOver(),
+		Results: make(map[string]directionResult),
+	}
+	for d, r := range e.session.GetResults() {
+		resp.Results[d.String()] = directionResult{Correct: r.Correct, Asked: r.Asked}
+	}
+	return resp
+}
+
+// newSessionID returns a random identifier for a session.
+func newSessionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate a session id: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeJSON writes the value as the JSON body of the response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		tools.Warning(fmt.Sprintf("failed to write the response: %v", err))
+	}
+}
+
+// apiError is the body of the responses in error.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// writeError writes the error as the JSON body of the response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
@@ -0,0 +1,302 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/internal/tests"
+	"github.com/boris-lenzinger/repeatit/parsing"
+	"github.com/boris-lenzinger/repeatit/tools"
+)
+
+func startTestServer(t *testing.T) *httptest.Server {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	ts := httptest.NewServer(New(topic, datamodel.NewInterrogationParameters()).Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func call(t *testing.T, ts *httptest.Server, method, path string, body interface{}, expectedStatus int, v interface{}) {
+	var r *bytes.Reader
+	if body != nil {
+		ba, _ := json.Marshal(body)
+		r = bytes.NewReader(ba)
+	} else {
+		r = bytes.NewReader(nil)
+	}
+	req, _ := http.NewRequest(method, ts.URL+path, r)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s must not fail. Received: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	ba, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != expectedStatus {
+		t.Fatalf("%s %s: expected status %d but got %d: %s", method, path, expectedStatus, resp.StatusCode, ba)
+	}
+	if v != nil {
+		if err := json.Unmarshal(ba, v); err != nil {
+			t.Fatalf("%s %s: invalid response %s: %v", method, path, ba, err)
+		}
+	}
+}
+
+func TestServeIndex(t *testing.T) {
+	ts := startTestServer(t)
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("getting the web page must not fail. Received: %v", err)
+	}
+	defer resp.Body.Close()
+	ba, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(ba), "/api/sessions") {
+		t.Errorf("The web page must be served. Got status %d", resp.StatusCode)
+	}
+}
+
+func TestServeLessons(t *testing.T) {
+	ts := startTestServer(t)
+	var lessons []lessonInfo
+	call(t, ts, http.MethodGet, lessonsPath, nil, http.StatusOK, &lessons)
+	if len(lessons) != 3 || lessons[2].ID != "03" || lessons[2].Words != 3 {
+		t.Errorf("Expected the 3 lessons of the sample but got %+v", lessons)
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	ts := startTestServer(t)
+	var started startResponse
+	call(t, ts, http.MethodPost, sessionsPath, startRequest{Lessons: "2", Mode: "linear", Limit: 1}, http.StatusCreated, &started)
+	if started.Questions != 2 || started.Limit != 1 {
+		t.Fatalf("Expected a session of 2 questions in 1 loop but got %+v", started)
+	}
+	base := sessionsPath + "/" + started.ID
+
+	var card cardResponse
+	call(t, ts, http.MethodPost, base+"/next", nil, http.StatusOK, &card)
+	if card.Over || card.Question != "2_Question 1" {
+		t.Fatalf("Expected the first question but got %+v", card)
+	}
+	var graded answerResponse
+	call(t, ts, http.MethodPost, base+"/answer", answerRequest{Attempt: "2_answer 1"}, http.StatusOK, &graded)
+	if !graded.Correct || graded.Answer != "2_Answer 1" {
+		t.Errorf("Expected a correct answer but got %+v", graded)
+	}
+	call(t, ts, http.MethodPost, base+"/answer", answerRequest{Attempt: "again"}, http.StatusConflict, nil)
+
+	call(t, ts, http.MethodPost, base+"/next", nil, http.StatusOK, &card)
+	var revealed answerResponse
+	call(t, ts, http.MethodPost, base+"/reveal", nil, http.StatusOK, &revealed)
+	if revealed.Answer != "2_Answer 2" {
+		t.Errorf("Expected the answer of the second question but got %+v", revealed)
+	}
+	call(t, ts, http.MethodPost, base+"/next", nil, http.StatusOK, &card)
+	if !card.Over {
+		t.Errorf("The session must be over after 2 questions. Got %+v", card)
+	}
+
+	var stats statsResponse
+	call(t, ts, http.MethodGet, base+"/stats", nil, http.StatusOK, &stats)
+	if r := stats.Results["production"]; !stats.Over || r.Asked != 1 || r.Correct != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	call(t, ts, http.MethodDelete, base, nil, http.StatusNoContent, nil)
+	call(t, ts, http.MethodGet, base+"/stats", nil, http.StatusNotFound, nil)
+}
+
+// TestSessionsAreEvicted checks that the finished sessions and the ones
+// nobody uses are forgotten by the server.
+func TestSessionsAreEvicted(t *testing.T) {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	s := New(topic, datamodel.NewInterrogationParameters())
+	s.idleTimeout, s.finishedTimeout = 500*time.Millisecond, 100*time.Millisecond
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	var finished, idle startResponse
+	call(t, ts, http.MethodPost, sessionsPath, startRequest{Lessons: "1", Mode: "linear", Limit: 1}, http.StatusCreated, &finished)
+	call(t, ts, http.MethodPost, sessionsPath, startRequest{Lessons: "2", Mode: "linear", Limit: 1}, http.StatusCreated, &idle)
+	var card cardResponse
+	for !card.Over {
+		call(t, ts, http.MethodPost, sessionsPath+"/"+finished.ID+"/next", nil, http.StatusOK, &card)
+	}
+	// the results of a session just finished can still be read
+	call(t, ts, http.MethodGet, sessionsPath+"/"+finished.ID+"/stats", nil, http.StatusOK, nil)
+
+	time.Sleep(200 * time.Millisecond)
+	var sessions []sessionInfo
+	call(t, ts, http.MethodGet, sessionsPath, nil, http.StatusOK, &sessions)
+	if len(sessions) != 1 || sessions[0].ID != idle.ID {
+		t.Errorf("Expected only the unfinished session %s but got %+v", idle.ID, sessions)
+	}
+	call(t, ts, http.MethodGet, sessionsPath+"/"+finished.ID+"/stats", nil, http.StatusNotFound, nil)
+
+	time.Sleep(time.Second)
+	call(t, ts, http.MethodGet, sessionsPath, nil, http.StatusOK, &sessions)
+	if len(sessions) != 0 {
+		t.Errorf("Expected the idle session to be evicted but got %+v", sessions)
+	}
+}
+
+// TestSessionsHaveTheirOwnRandomSource checks that the sessions driven at
+// the same time do not share a random source and that the seed returned
+// replays the session.
+func TestSessionsHaveTheirOwnRandomSource(t *testing.T) {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	s := New(topic, datamodel.NewInterrogationParameters())
+	first, err := s.buildParameters(startRequest{Lessons: "3", Mode: "random"})
+	if err != nil {
+		t.Fatalf("building the parameters must not fail. Received: %v", err)
+	}
+	second, _ := s.buildParameters(startRequest{Lessons: "3", Mode: "random"})
+	if first.GetRandom() == second.GetRandom() || first.GetRandom() == s.params.GetRandom() {
+		t.Fatalf("Each session must have its own random source")
+	}
+	seed := first.GetSeed()
+	replayed, _ := s.buildParameters(startRequest{Lessons: "3", Mode: "random", Seed: &seed})
+	for i := 0; i < 10; i++ {
+		if expected, got := first.GetRandom().Int63(), replayed.GetRandom().Int63(); expected != got {
+			t.Fatalf("The seed %d must replay the session: expected %d but got %d", seed, expected, got)
+		}
+	}
+}
+
+// TestSessionsShareTheHistory checks that deleting a session before its
+// end does not save it to be resumed, and that the results of the sessions
+// driven at the same time are all kept in the history.
+func TestSessionsShareTheHistory(t *testing.T) {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	dir := t.TempDir()
+	lessonsFile := filepath.Join(dir, "lessons.txt")
+	if err := ioutil.WriteFile(lessonsFile, []byte(tests.GetSampleCsvAsStream()), 0644); err != nil {
+		t.Fatalf("writing the lessons file must not fail. Received: %v", err)
+	}
+	p := datamodel.NewInterrogationParameters()
+	p.SetLessonsFile(lessonsFile)
+	p.SetDataDir(dir)
+	ts := httptest.NewServer(New(topic, p).Handler())
+	defer ts.Close()
+
+	var first, second startResponse
+	call(t, ts, http.MethodPost, sessionsPath, startRequest{Lessons: "2", Mode: "linear", Limit: 1}, http.StatusCreated, &first)
+	call(t, ts, http.MethodPost, sessionsPath, startRequest{Lessons: "2", Mode: "linear", Limit: 1}, http.StatusCreated, &second)
+	answer := func(started startResponse, attempt string) {
+		base := sessionsPath + "/" + started.ID
+		call(t, ts, http.MethodPost, base+"/next", nil, http.StatusOK, nil)
+		call(t, ts, http.MethodPost, base+"/answer", answerRequest{Attempt: attempt}, http.StatusOK, nil)
+	}
+	answer(first, "2_Answer 1")
+	answer(second, "2_Answer 1")
+	// the first session is left before its end
+	call(t, ts, http.MethodDelete, sessionsPath+"/"+first.ID, nil, http.StatusNoContent, nil)
+	answer(second, "wrong")
+	call(t, ts, http.MethodDelete, sessionsPath+"/"+second.ID, nil, http.StatusNoContent, nil)
+
+	if exists, _ := tools.FileExists(p.GetSnapshotFile()); exists {
+		t.Errorf("A session deleted through the API must not be saved to be resumed")
+	}
+	history, err := datamodel.LoadHistory(p.GetHistoryFile())
+	if err != nil {
+		t.Fatalf("loading the history must not fail. Received: %v", err)
+	}
+	asked := 0
+	for _, r := range history.Items {
+		asked += r.Asked
+	}
+	if asked != 3 {
+		t.Errorf("Expected the 3 answers of both sessions in the history but got %d", asked)
+	}
+}
+
+func TestStartSessionWithInvalidSettings(t *testing.T) {
+	ts := startTestServer(t)
+	for _, req := range []startRequest{
+		{Lessons: "a:b"},
+		{Lessons: "1", Mode: "chaos"},
+		{Lessons: "1", Exercise: "dance"},
+		{Lessons: "1", Limit: -2},
+		{Lessons: "42"},
+	} {
+		call(t, ts, http.MethodPost, sessionsPath, req, http.StatusBadRequest, nil)
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	ts := startTestServer(t)
+	var started startResponse
+	call(t, ts, http.MethodPost, sessionsPath, startRequest{Lessons: "1", Mode: "linear", Limit: 1}, http.StatusCreated, &started)
+	var running []sessionInfo
+	call(t, ts, http.MethodGet, sessionsPath, nil, http.StatusOK, &running)
+	if len(running) != 1 || running[0].ID != started.ID {
+		t.Fatalf("Expected the session %q to be listed but got %+v", started.ID, running)
+	}
+	base := sessionsPath + "/" + started.ID
+
+	// two subscribers receive the same events
+	var streams []*bufio.Reader
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(ts.URL + base + "/events")
+		if err != nil {
+			t.Fatalf("opening the events stream must not fail. Received: %v", err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Expected an event stream but got %q", ct)
+		}
+		streams = append(streams, bufio.NewReader(resp.Body))
+	}
+
+	call(t, ts, http.MethodPost, base+"/next", nil, http.StatusOK, nil)
+	call(t, ts, http.MethodPost, base+"/answer", answerRequest{Attempt: "wrong"}, http.StatusOK, nil)
+	call(t, ts, http.MethodPost, base+"/next", nil, http.StatusOK, nil)
+
+	expected := []string{"loop-started", "question-asked", "answer-graded", "answer-revealed", "session-ended"}
+	for n, stream := range streams {
+		var kinds []string
+		var graded engine.EventMessage
+		for {
+			line, err := stream.ReadString('\n')
+			if err != nil {
+				break
+			}
+			if strings.HasPrefix(line, "data: ") {
+				var msg engine.EventMessage
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg); err != nil {
+					t.Fatalf("invalid event %q: %v", line, err)
+				}
+				if msg.Kind == "answer-graded" {
+					graded = msg
+				}
+				kinds = append(kinds, msg.Kind)
+			}
+		}
+		if strings.Join(kinds, ",") != strings.Join(expected, ",") {
+			t.Errorf("Subscriber %d: expected the events %v but got %v", n, expected, kinds)
+		}
+		if graded.Question != "1_Question 1" || graded.Attempt != "wrong" || graded.Correct == nil || *graded.Correct {
+			t.Errorf("Subscriber %d: unexpected graded event %+v", n, graded)
+		}
+	}
+}
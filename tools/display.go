@@ -59,6 +59,26 @@ func WriteInYellow(msg string) {
 	c.Printf(msg)
 }
 
+// genderColors are the colors of the nouns by gender: blue for the
+// masculine, red for the feminine and green for the neuter, as in most
+// dictionaries for learners.
+var genderColors = map[string]color.Attribute{
+	"m": color.FgBlue,
+	"f": color.FgRed,
+	"n": color.FgGreen,
+}
+
+// ColorByGender returns the text colored after the grammatical gender
+// passed in parameter (m, f or n). The text is returned as is if the
+// gender is unknown.
+func ColorByGender(text string, gender string) string {
+	attribute, ok := genderColors[gender]
+	if !ok {
+		return text
+	}
+	return color.New(attribute).Sprint(text)
+}
+
 // Error gives a convenient and uniform way to report error to the user console.
 // This function uses ASCII code to display color so it can make log files look
 // like a little bit funny. Use less -R to view log files with colors.
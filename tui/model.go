@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/boris-lenzinger/repeatit/engine"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// model is the state of the card view. It turns the keys pressed by the
+// user into calls to the session.
+type model struct {
+	session *engine.Session
+	card    engine.Card
+	// answer is set once the answer is revealed
+	answer   string
+	revealed bool
+	// hint is the answer partially masked, the more hints the user asks
+	// the more letters are visible
+	hint      string
+	hintLevel int
+	flagged   bool
+	// status is a short message displayed under the card
+	status string
+	over   bool
+}
+
+// newModel creates the model and draws the first card.
+func newModel(session *engine.Session) *model {
+	m := &model{session: session}
+	m.next()
+	return m
+}
+
+// next draws the next card of the session.
+func (m *model) next() {
+	card, ok := m.session.Next()
+	if !ok {
+		m.over = true
+		return
+	}
+	m.card = card
+	m.answer, m.revealed = "", false
+	m.hint, m.hintLevel = "", 0
+	m.flagged = m.session.IsFlagged()
+}
+
+// reveal shows the answer of the card.
+func (m *model) reveal() {
+	if m.revealed {
+		return
+	}
+	answer, err := m.session.Reveal()
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.answer, m.revealed = answer, true
+}
+
+// handleKey reacts to a key pressed by the user. It returns true if the
+// user wants to leave.
+func (m *model) handleKey(key termbox.Key, ch rune) bool {
+	m.status = ""
+	if key == termbox.KeyEsc || key == termbox.KeyCtrlC || ch == 'q' {
+		return true
+	}
+	if m.over {
+		// any key leaves the summary
+		return true
+	}
+	switch {
+	case key == termbox.KeySpace || key == termbox.KeyEnter:
+		if m.revealed {
+			m.next()
+		} else {
+			m.reveal()
+		}
+	case ch >= '1' && ch <= '4':
+		g := engine.Grade(ch - '0')
+		m.reveal()
+		if err := m.session.Grade(g); err != nil {
+			m.status = err.Error()
+			return false
+		}
+		m.next()
+		m.status = fmt.Sprintf("Previous card graded %s", g)
+	case ch == 's':
+		m.next()
+		m.status = "Previous card skipped"
+	case ch == 'h':
+		if m.revealed {
+			return false
+		}
+		m.hintLevel++
+		hint, err := m.session.Hint(m.hintLevel)
+		if err != nil {
+			m.status = err.Error()
+			return false
+		}
+		m.hint = hint
+	case ch == 'f':
+		flagged, err := m.session.ToggleFlag()
+		if err != nil {
+			m.status = err.Error()
+			return false
+		}
+		m.flagged = flagged
+		if flagged {
+			m.status = "Card flagged for review"
+		} else {
+			m.status = "Flag removed"
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	runewidth "github.com/mattn/go-runewidth"
+	termbox "github.com/nsf/termbox-go"
+)
+
+const (
+	// sidePanelWidth is the width of the panel with the statistics
+	sidePanelWidth = 32
+	// minWidthForSidePanel is the width of the screen under which the side
+	// panel is hidden to leave the room to the card
+	minWidthForSidePanel = 72
+	// keysHelp is displayed at the bottom of the screen
+	keysHelp = "space: reveal/next  1-4: grade  s: skip  h: hint  f: flag  q: quit"
+)
+
+// canvas is where the view is drawn. It is the screen of termbox but it can
+// be replaced in the tests.
+type canvas interface {
+	SetCell(x, y int, ch rune, fg, bg termbox.Attribute)
+}
+
+// termboxCanvas draws on the terminal.
+type termboxCanvas struct{}
+
+// SetCell draws a character on the terminal.
+func (termboxCanvas) SetCell(x, y int, ch rune, fg, bg termbox.Attribute) {
+	termbox.SetCell(x, y, ch, fg, bg)
+}
+
+// render draws the whole view on a screen of width x height cells. The
+// layout is computed from the size so the view adapts when the terminal is
+// resized.
+func (m *model) render(c canvas, width, height int) {
+	if width < 10 || height < 6 {
+		drawText(c, 0, 0, width, termbox.ColorRed, "Too small")
+		return
+	}
+	cardWidth := width
+	if width >= minWidthForSidePanel {
+		cardWidth = width - sidePanelWidth
+		m.renderSidePanel(c, cardWidth, 0, sidePanelWidth, height-1)
+	}
+	if m.over {
+		m.renderSummary(c, 0, 0, cardWidth, height-1)
+	} else {
+		m.renderCard(c, 0, 0, cardWidth, height-1)
+	}
+	drawText(c, 0, height-1, width, termbox.ColorCyan, keysHelp)
+}
+
+// renderCard draws the question, the hint or the answer and the progress
+// of the loop.
+func (m *model) renderCard(c canvas, x, y, width, height int) {
+	drawBox(c, x, y, width, height-2)
+	inner := width - 4
+	lines := []string{}
+	title := "Question"
+	if m.card.Direction == datamodel.Recognition {
+		title = "Question (recognition)"
+	}
+	if m.flagged {
+		title += " [flagged]"
+	}
+	drawText(c, x+2, y, inner, termbox.ColorYellow|termbox.AttrBold, " "+title+" ")
+	lines = append(lines, wrapText(m.card.Question, inner)...)
+	lines = append(lines, "")
+	switch {
+	case m.revealed:
+		lines = append(lines, wrapText("--> "+m.answer, inner)...)
+	case m.hint != "":
+		lines = append(lines, wrapText("hint: "+m.hint, inner)...)
+	}
+	top := y + 1 + (height-2-2-len(lines))/2
+	if top < y+1 {
+		top = y + 1
+	}
+	for i, line := range lines {
+		if top+i >= y+height-3 {
+			break
+		}
+		fg := termbox.ColorDefault | termbox.AttrBold
+		if i > 0 {
+			fg = termbox.ColorGreen
+		}
+		drawText(c, x+2+(inner-runewidth.StringWidth(line))/2, top+i, inner, fg, line)
+	}
+	n := m.session.GetQuestionsCount()
+	done := m.session.GetQuestionsAskedInLoop()
+	label := fmt.Sprintf(" %d/%d", done, n)
+	drawProgressBar(c, x, y+height-2, width-len(label), done, n)
+	drawText(c, x+width-len(label), y+height-2, len(label), termbox.ColorDefault, label)
+	drawText(c, x, y+height-1, width, termbox.ColorMagenta, m.status)
+}
+
+// renderSummary draws the results once the session is over.
+func (m *model) renderSummary(c canvas, x, y, width, height int) {
+	drawBox(c, x, y, width, height)
+	drawText(c, x+2, y, width-4, termbox.ColorYellow|termbox.AttrBold, " Session over ")
+	row := y + 2
+	for _, line := range m.resultsLines() {
+		drawText(c, x+2, row, width-4, termbox.ColorDefault, line)
+		row++
+	}
+	drawText(c, x+2, row+1, width-4, termbox.ColorCyan, "Press any key to leave.")
+}
+
+// renderSidePanel draws the statistics of the lesson and of the session.
+func (m *model) renderSidePanel(c canvas, x, y, width, height int) {
+	drawBox(c, x, y, width, height)
+	drawText(c, x+2, y, width-4, termbox.ColorYellow|termbox.AttrBold, " Stats ")
+	lines := []string{
+		"Lessons: " + strings.Join(m.session.GetSubsections(), ","),
+		fmt.Sprintf("Loop: %d/%d", m.session.GetLoop(), m.session.GetLimit()),
+		fmt.Sprintf("Asked: %d", m.session.GetQuestionsAsked()),
+		"",
+	}
+	lines = append(lines, m.resultsLines()...)
+	if !m.over {
+		lines = append(lines, "", "This card:")
+		if r := m.session.GetRecord(); r != nil {
+			lines = append(lines, fmt.Sprintf("  %d misses out of %d", r.Misses, r.Asked))
+		} else {
+			lines = append(lines, "  never graded")
+		}
+	}
+	for i, line := range lines {
+		if y+1+i >= y+height-1 {
+			break
+		}
+		drawText(c, x+2, y+1+i, width-4, termbox.ColorDefault, line)
+	}
+}
+
+// resultsLines describes the graded answers of the session.
+func (m *model) resultsLines() []string {
+	results := m.session.GetResults()
+	if len(results) == 0 {
+		return []string{"No answer graded yet"}
+	}
+	lines := []string{}
+	for _, d := range []datamodel.Direction{datamodel.Production, datamodel.Recognition} {
+		if r, ok := results[d]; ok {
+			lines = append(lines, fmt.Sprintf("%s: %d/%d", d, r.Correct, r.Asked))
+		}
+	}
+	return lines
+}
+
+// drawText writes the text from (x, y), cutting it at width cells.
+func drawText(c canvas, x, y, width int, fg termbox.Attribute, text string) {
+	used := 0
+	for _, r := range text {
+		w := runewidth.RuneWidth(r)
+		if used+w > width {
+			return
+		}
+		c.SetCell(x+used, y, r, fg, termbox.ColorDefault)
+		used += w
+	}
+}
+
+// drawBox draws the border of a rectangle.
+func drawBox(c canvas, x, y, width, height int) {
+	fg, bg := termbox.ColorBlue, termbox.ColorDefault
+	for i := x + 1; i < x+width-1; i++ {
+		c.SetCell(i, y, '─', fg, bg)
+		c.SetCell(i, y+height-1, '─', fg, bg)
+	}
+	for j := y + 1; j < y+height-1; j++ {
+		c.SetCell(x, j, '│', fg, bg)
+		c.SetCell(x+width-1, j, '│', fg, bg)
+	}
+	c.SetCell(x, y, '┌', fg, bg)
+	c.SetCell(x+width-1, y, '┐', fg, bg)
+	c.SetCell(x, y+height-1, '└', fg, bg)
+	c.SetCell(x+width-1, y+height-1, '┘', fg, bg)
+}
+
+// drawProgressBar draws the share of the loop already done.
+func drawProgressBar(c canvas, x, y, width, done, total int) {
+	if width <= 0 || total == 0 {
+		return
+	}
+	filled := done * width / total
+	for i := 0; i < width; i++ {
+		ch, fg := '░', termbox.ColorDefault
+		if i < filled {
+			ch, fg = '█', termbox.ColorGreen
+		}
+		c.SetCell(x+i, y, ch, fg, termbox.ColorDefault)
+	}
+}
+
+// wrapText splits the text in lines of at most width cells, on the spaces
+// when possible.
+func wrapText(text string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for runewidth.StringWidth(word) > width {
+			// the word does not fit on a line: it is cut
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			cut := runewidth.Truncate(word, width, "")
+			lines = append(lines, cut)
+			word = word[len(cut):]
+		}
+		switch {
+		case line == "":
+			line = word
+		case runewidth.StringWidth(line)+1+runewidth.StringWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,44 @@
+// Package tui displays a study session full screen in the terminal: one
+// card at a time, with keyboard shortcuts and the statistics of the session.
+package tui
+
+import (
+	"github.com/boris-lenzinger/repeatit/engine"
+	termbox "github.com/nsf/termbox-go"
+	"github.com/pkg/errors"
+)
+
+// Run displays the session full screen until it is over or the user leaves.
+// The caller is responsible for closing the session, which saves it if it
+// is not over.
+func Run(session *engine.Session) error {
+	if err := termbox.Init(); err != nil {
+		return errors.Wrap(err, "failed to initialize the terminal")
+	}
+	defer termbox.Close()
+	termbox.SetInputMode(termbox.InputEsc)
+
+	m := newModel(session)
+	draw(m)
+	for {
+		switch ev := termbox.PollEvent(); ev.Type {
+		case termbox.EventKey:
+			if m.handleKey(ev.Key, ev.Ch) {
+				return nil
+			}
+		case termbox.EventResize:
+			// the layout is computed from the new size
+		case termbox.EventError:
+			return errors.Wrap(ev.Err, "failed to read the keyboard")
+		}
+		draw(m)
+	}
+}
+
+// draw renders the model on the whole terminal.
+func draw(m *model) {
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	width, height := termbox.Size()
+	m.render(termboxCanvas{}, width, height)
+	termbox.Flush()
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/boris-lenzinger/repeatit/datamodel"
+	"github.com/boris-lenzinger/repeatit/engine"
+	"github.com/boris-lenzinger/repeatit/internal/tests"
+	"github.com/boris-lenzinger/repeatit/parsing"
+	termbox "github.com/nsf/termbox-go"
+)
+
+// fakeCanvas records the cells drawn so the tests can read the screen.
+type fakeCanvas struct {
+	width, height int
+	cells         [][]rune
+}
+
+func newFakeCanvas(width, height int) *fakeCanvas {
+	c := &fakeCanvas{width: width, height: height, cells: make([][]rune, height)}
+	for y := range c.cells {
+		c.cells[y] = []rune(strings.Repeat(" ", width))
+	}
+	return c
+}
+
+func (c *fakeCanvas) SetCell(x, y int, ch rune, fg, bg termbox.Attribute) {
+	if x < 0 || y < 0 || x >= c.width || y >= c.height {
+		panic("drawing outside of the screen")
+	}
+	c.cells[y][x] = ch
+}
+
+func (c *fakeCanvas) String() string {
+	lines := make([]string, len(c.cells))
+	for y, line := range c.cells {
+		lines[y] = string(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func getSampleModel(t *testing.T) *model {
+	topic, err := parsing.ParseTopic(strings.NewReader(tests.GetSampleCsvAsStream()), datamodel.NewTopicParsingParameters())
+	if err != nil {
+		t.Fatalf("parsing sample csv must not fail. Received: %v", err)
+	}
+	p := datamodel.NewInterrogationParameters()
+	p.SetLinearMode()
+	p.SetLimit(1)
+	p.SetExercise(datamodel.ExerciseVocabulary)
+	p.SetListOfSubsections("03")
+	session, err := engine.NewSession(topic, p, nil)
+	if err != nil {
+		t.Fatalf("creating a session must not fail. Received: %v", err)
+	}
+	return newModel(session)
+}
+
+func TestModelKeys(t *testing.T) {
+	m := getSampleModel(t)
+	if m.card.Question != "3_Question 1" {
+		t.Fatalf("Expected the first card to be shown but got %q", m.card.Question)
+	}
+	m.handleKey(0, 'h')
+	if m.hint != "3_______ 1" {
+		t.Errorf("Expected a hint with the first letters but got %q", m.hint)
+	}
+	m.handleKey(termbox.KeySpace, 0)
+	if !m.revealed || m.answer != "3_Answer 1" {
+		t.Errorf("Space must reveal the answer. Got %q", m.answer)
+	}
+	m.handleKey(termbox.KeySpace, 0)
+	if m.card.Question != "3_Question 2" || m.revealed {
+		t.Errorf("Space must show the next card once revealed. Got %q", m.card.Question)
+	}
+	m.handleKey(0, 'f')
+	if !m.flagged {
+		t.Errorf("f must flag the card")
+	}
+	m.handleKey(0, '1')
+	if m.card.Question != "3_Question 3" {
+		t.Errorf("Grading must show the next card. Got %q", m.card.Question)
+	}
+	if r := m.session.GetResults()[datamodel.Production]; r.Asked != 1 || r.Correct != 0 {
+		t.Errorf("Grade 1 must count as a wrong answer. Got %+v", r)
+	}
+	m.handleKey(0, 's')
+	if !m.over {
+		t.Errorf("The session must be over after the last card")
+	}
+	if !m.handleKey(0, 'x') {
+		t.Errorf("Any key must leave once the session is over")
+	}
+}
+
+func TestRenderAdaptsToTheSize(t *testing.T) {
+	m := getSampleModel(t)
+	for _, size := range [][2]int{{120, 40}, {80, 24}, {50, 12}, {20, 8}, {5, 3}} {
+		c := newFakeCanvas(size[0], size[1])
+		m.render(c, size[0], size[1])
+		screen := c.String()
+		if size[0] >= 50 && !strings.Contains(screen, m.card.Question) {
+			t.Errorf("The question must be displayed on a %dx%d screen:\n%s", size[0], size[1], screen)
+		}
+		if hasPanel := strings.Contains(screen, "Stats"); hasPanel != (size[0] >= minWidthForSidePanel) {
+			t.Errorf("The side panel must only be displayed on wide screens (%dx%d):\n%s", size[0], size[1], screen)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("the quick brown fox jumps over the lazy dog", 10)
+	for _, line := range lines {
+		if len(line) > 10 {
+			t.Errorf("Line %q is wider than 10", line)
+		}
+	}
+	if strings.Join(lines, " ") != "the quick brown fox jumps over the lazy dog" {
+		t.Errorf("No word must be lost: %v", lines)
+	}
+	if lines := wrapText("abcdefghijkl", 5); strings.Join(lines, "") != "abcdefghijkl" {
+		t.Errorf("Long words must be cut: %v", lines)
+	}
+}